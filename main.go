@@ -4,9 +4,16 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"api.devbook/src/config"
+	"api.devbook/src/database"
+	"api.devbook/src/repository"
 	"api.devbook/src/router"
+	"api.devbook/src/search"
+	"api.devbook/src/storage"
+	"api.devbook/src/timeline"
+	"api.devbook/src/worker"
 )
 
 // func init() {
@@ -23,8 +30,63 @@ import (
 func main() {
 	config.Load()
 
+	localStorage, err := storage.NewLocal(config.UploadDir, "/media")
+	if err != nil {
+		log.Fatal(err)
+	}
+	storage.Default = localStorage
+
+	switch config.TimelineStore {
+	case "redis":
+		redisStore, err := timeline.NewRedis(config.RedisAddress)
+		if err != nil {
+			log.Fatal(err)
+		}
+		timeline.Default = redisStore
+	default:
+		timeline.Default = timeline.NewMemory()
+	}
+
+	searchIndex, err := loadSearchIndex()
+	if err != nil {
+		log.Fatal(err)
+	}
+	search.Default = searchIndex
+
+	worker.Every(time.Hour, "coleta de mídias órfãs", worker.CollectOrphanMedia)
+	worker.Every(time.Minute, "publicação das agendadas", worker.PublishScheduled)
+	worker.Every(time.Minute, "exclusão das expiradas", worker.DeleteExpired)
+
 	r := router.Create()
 
 	fmt.Printf("Escutando na porta %d", config.Port)
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.Port), r))
 }
+
+// loadSearchIndex cria o índice de busca configurado. O índice em memória é carregado com todas as
+// publicações do banco de dados, enquanto o do MySQL mantém uma conexão aberta para as consultas
+func loadSearchIndex() (search.Index, error) {
+	db, err := database.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.SearchIndex != "memory" {
+		return search.NewMySQL(db, repository.ViewableClause), nil
+	}
+	defer db.Close()
+
+	publications, err := repository.NewRepositoryOfPublications(db).GetAllForSearch()
+	if err != nil {
+		return nil, err
+	}
+
+	index := search.NewMemory()
+	for _, publication := range publications {
+		if err = index.Add(search.DocumentOf(publication)); err != nil {
+			return nil, err
+		}
+	}
+
+	return index, nil
+}
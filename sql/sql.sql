@@ -2,6 +2,24 @@ CREATE DATABASE IF NOT EXISTS devbook;
 
 USE devbook;
 
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_voters;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
+DROP TABLE IF EXISTS publication_revisions;
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS bookmark_collections;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS publication_mentions;
+DROP TABLE IF EXISTS tag_followers;
+DROP TABLE IF EXISTS publication_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS publication_reactions;
+DROP TABLE IF EXISTS publication_media;
+DROP TABLE IF EXISTS media;
+DROP TABLE IF EXISTS follow_requests;
+DROP TABLE IF EXISTS mutes;
+DROP TABLE IF EXISTS blocks;
 DROP TABLE IF EXISTS publications;
 DROP TABLE IF EXISTS followers;
 DROP TABLE IF EXISTS users;
@@ -12,8 +30,18 @@ CREATE TABLE users(
     nick varchar(50) not null unique,
     email varchar(50) not null unique,
     password varchar(100) not null,
+    private boolean default false not null,
+    admin boolean default false not null,
+    bio varchar(160) default '' not null,
+    location varchar(50) default '' not null,
+    links json,
+    pronouns varchar(30) default '' not null,
+    birthday date,
+    birthdayVisibility varchar(10) default 'private' not null,
+    avatar varchar(255) default '' not null,
+    header varchar(255) default '' not null,
     createdAt timestamp default current_timestamp() not null
-) ENGINE = INNODB;
+) ENGINE = INNODB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
 CREATE TABLE followers(
     userId int not null,
@@ -26,19 +54,345 @@ CREATE TABLE followers(
     REFERENCES users(id)
     ON DELETE CASCADE,
 
+    createdAt timestamp default current_timestamp() not null,
+
     primary key(userId, followerId)
 ) ENGINE=INNODB;
 
 CREATE TABLE publications(
     id int auto_increment primary key,
     title varchar(50) not null,
-    content varchar(300) not null,
+    content varchar(1000) not null,
     
     authorId int not null,
     FOREIGN KEY (authorId)
     REFERENCES users(id)
     ON DELETE CASCADE,
 
-    likes int default 0,
-    createdAt timestamp default current_timestamp()
-) ENGINE=INNODB;
\ No newline at end of file
+    reactions int default 0 not null,
+    reposts int default 0 not null,
+    quotes int default 0 not null,
+
+    repostOfId int,
+    FOREIGN KEY (repostOfId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    quotedId int,
+
+    threadId int,
+    FOREIGN KEY (threadId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+    threadPosition int default 1 not null,
+
+    status varchar(10) default 'published' not null,
+    visibility varchar(10) default 'public' not null,
+    publishAt timestamp null,
+    editedAt timestamp null,
+    pinnedAt timestamp null,
+    expiresAt timestamp null,
+
+    createdAt timestamp default current_timestamp(),
+
+    INDEX (authorId, createdAt, id),
+    INDEX (status, publishAt),
+    INDEX (expiresAt),
+    INDEX (createdAt, id),
+    INDEX (quotedId),
+    UNIQUE (authorId, repostOfId),
+    UNIQUE (threadId, threadPosition),
+    FULLTEXT (title, content)
+) ENGINE=INNODB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE blocks(
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    blockedId int not null,
+    FOREIGN KEY (blockedId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    createdAt timestamp default current_timestamp() not null,
+
+    primary key(userId, blockedId)
+) ENGINE=INNODB;
+
+CREATE TABLE mutes(
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    mutedId int not null,
+    FOREIGN KEY (mutedId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    createdAt timestamp default current_timestamp() not null,
+
+    primary key(userId, mutedId)
+) ENGINE=INNODB;
+
+CREATE TABLE follow_requests(
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    requesterId int not null,
+    FOREIGN KEY (requesterId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    createdAt timestamp default current_timestamp() not null,
+
+    primary key(userId, requesterId)
+) ENGINE=INNODB;
+
+CREATE TABLE media(
+    id int auto_increment primary key,
+
+    ownerId int not null,
+    FOREIGN KEY (ownerId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    purpose varchar(20) not null,
+    storageKey varchar(100) not null unique,
+    contentType varchar(50) not null,
+    width int not null,
+    height int not null,
+    size int not null,
+    createdAt timestamp default current_timestamp() not null
+) ENGINE=INNODB;
+
+CREATE TABLE publication_media(
+    publicationId int not null,
+    FOREIGN KEY (publicationId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    mediaId int not null unique,
+    FOREIGN KEY (mediaId)
+    REFERENCES media(id)
+    ON DELETE CASCADE,
+
+    position int not null,
+    altText varchar(1000) not null,
+
+    primary key(publicationId, mediaId)
+) ENGINE=INNODB;
+
+CREATE TABLE publication_reactions(
+    publicationId int not null,
+    FOREIGN KEY (publicationId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    type varchar(20) not null,
+    createdAt timestamp default current_timestamp() not null,
+
+    primary key(publicationId, userId),
+    INDEX (userId)
+) ENGINE=INNODB;
+
+CREATE TABLE tags(
+    id int auto_increment primary key,
+    name varchar(50) not null unique,
+    createdAt timestamp default current_timestamp() not null
+) ENGINE=INNODB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE publication_tags(
+    publicationId int not null,
+    FOREIGN KEY (publicationId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    tagId int not null,
+    FOREIGN KEY (tagId)
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+
+    createdAt timestamp default current_timestamp() not null,
+
+    primary key(tagId, publicationId),
+    INDEX (publicationId),
+    INDEX (createdAt)
+) ENGINE=INNODB;
+
+CREATE TABLE tag_followers(
+    tagId int not null,
+    FOREIGN KEY (tagId)
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    createdAt timestamp default current_timestamp() not null,
+
+    primary key(tagId, userId),
+    INDEX (userId)
+) ENGINE=INNODB;
+
+CREATE TABLE publication_mentions(
+    publicationId int not null,
+    FOREIGN KEY (publicationId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    startOffset int not null,
+    endOffset int not null,
+
+    primary key(publicationId, startOffset),
+    INDEX (userId)
+) ENGINE=INNODB;
+
+CREATE TABLE notifications(
+    id int auto_increment primary key,
+
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    actorId int not null,
+    FOREIGN KEY (actorId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    type varchar(20) not null,
+
+    publicationId int,
+    FOREIGN KEY (publicationId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    readAt timestamp null,
+    createdAt timestamp default current_timestamp() not null,
+
+    INDEX (userId, createdAt, id)
+) ENGINE=INNODB;
+
+CREATE TABLE bookmark_collections(
+    id int auto_increment primary key,
+
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    name varchar(50) not null,
+    createdAt timestamp default current_timestamp() not null,
+
+    UNIQUE (userId, name)
+) ENGINE=INNODB;
+
+CREATE TABLE bookmarks(
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    publicationId int not null,
+    FOREIGN KEY (publicationId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    collectionId int,
+    FOREIGN KEY (collectionId)
+    REFERENCES bookmark_collections(id)
+    ON DELETE SET NULL,
+
+    createdAt timestamp default current_timestamp() not null,
+
+    primary key(userId, publicationId),
+    INDEX (userId, createdAt, publicationId)
+) ENGINE=INNODB;
+
+CREATE TABLE publication_revisions(
+    id int auto_increment primary key,
+
+    publicationId int not null,
+    FOREIGN KEY (publicationId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    title varchar(50) not null,
+    content varchar(1000) not null,
+    createdAt timestamp default current_timestamp() not null,
+
+    INDEX (publicationId, createdAt, id)
+) ENGINE=INNODB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE polls(
+    publicationId int primary key,
+    FOREIGN KEY (publicationId)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    multiple boolean default false not null,
+    closesAt timestamp not null
+) ENGINE=INNODB;
+
+CREATE TABLE poll_options(
+    id int auto_increment primary key,
+
+    publicationId int not null,
+    FOREIGN KEY (publicationId)
+    REFERENCES polls(publicationId)
+    ON DELETE CASCADE,
+
+    position int not null,
+    text varchar(50) not null,
+
+    INDEX (publicationId, position)
+) ENGINE=INNODB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE poll_voters(
+    publicationId int not null,
+    FOREIGN KEY (publicationId)
+    REFERENCES polls(publicationId)
+    ON DELETE CASCADE,
+
+    userId int not null,
+    FOREIGN KEY (userId)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    createdAt timestamp default current_timestamp() not null,
+
+    primary key(publicationId, userId)
+) ENGINE=INNODB;
+
+CREATE TABLE poll_votes(
+    optionId int not null,
+    FOREIGN KEY (optionId)
+    REFERENCES poll_options(id)
+    ON DELETE CASCADE,
+
+    publicationId int not null,
+    userId int not null,
+    FOREIGN KEY (publicationId, userId)
+    REFERENCES poll_voters(publicationId, userId)
+    ON DELETE CASCADE,
+
+    primary key(optionId, userId),
+    INDEX (publicationId, userId)
+) ENGINE=INNODB;
@@ -5,7 +5,9 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
+	"api.devbook/src/ranking"
 	"github.com/joho/godotenv"
 )
 
@@ -18,6 +20,29 @@ var (
 
 	// SecretKey é a chave que vai ser usada para assinar os tokens
 	SecretKey []byte
+
+	// UploadDir é o diretório onde os arquivos enviados são armazenados
+	UploadDir = ""
+
+	// MaxUploadSize é o tamanho máximo, em bytes, de um arquivo enviado
+	MaxUploadSize int64 = 0
+
+	// TimelineStore é o armazenamento das linhas do tempo pré-calculadas: "memory" ou "redis"
+	TimelineStore = ""
+
+	// RedisAddress é o endereço do servidor compatível com o protocolo do Redis
+	RedisAddress = ""
+
+	// CelebrityThreshold é a quantidade de seguidores a partir da qual as publicações de um usuário
+	// deixam de ser distribuídas para as linhas do tempo e passam a ser buscadas na leitura
+	CelebrityThreshold = 0
+
+	// SearchIndex é o índice usado na busca de publicações: "mysql", com o índice FULLTEXT do banco, ou
+	// "memory", com um índice carregado na memória na inicialização
+	SearchIndex = ""
+
+	// FeedWeights são os pesos usados para ordenar o feed por relevância
+	FeedWeights = ranking.DefaultWeights
 )
 
 // Inicializa as variaveis de ambiente
@@ -48,4 +73,60 @@ func Load() {
 	)
 
 	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+
+	UploadDir = os.Getenv("UPLOAD_DIR")
+	if UploadDir == "" {
+		UploadDir = "uploads"
+	}
+
+	MaxUploadSize, err = strconv.ParseInt(os.Getenv("MAX_UPLOAD_SIZE"), 10, 64)
+	if err != nil {
+		MaxUploadSize = 5 << 20
+	}
+
+	TimelineStore = os.Getenv("TIMELINE_STORE")
+	if TimelineStore == "" {
+		TimelineStore = "memory"
+	}
+
+	RedisAddress = os.Getenv("REDIS_ADDRESS")
+	if RedisAddress == "" {
+		RedisAddress = "localhost:6379"
+	}
+
+	CelebrityThreshold, err = strconv.Atoi(os.Getenv("CELEBRITY_THRESHOLD"))
+	if err != nil {
+		CelebrityThreshold = 10000
+	}
+
+	SearchIndex = os.Getenv("SEARCH_INDEX")
+	if SearchIndex == "" {
+		SearchIndex = "mysql"
+	}
+
+	if hours, err := strconv.ParseFloat(os.Getenv("FEED_HALF_LIFE_HOURS"), 64); err == nil {
+		FeedWeights.HalfLife = time.Duration(hours * float64(time.Hour))
+	}
+
+	// FEED_WEIGHT_LIKES é o nome anterior do peso das reações
+	reactionsWeight := os.Getenv("FEED_WEIGHT_REACTIONS")
+	if reactionsWeight == "" {
+		reactionsWeight = os.Getenv("FEED_WEIGHT_LIKES")
+	}
+
+	if weight, err := strconv.ParseFloat(reactionsWeight, 64); err == nil {
+		FeedWeights.Reactions = weight
+	}
+
+	if weight, err := strconv.ParseFloat(os.Getenv("FEED_WEIGHT_QUOTES"), 64); err == nil {
+		FeedWeights.Quotes = weight
+	}
+
+	if weight, err := strconv.ParseFloat(os.Getenv("FEED_WEIGHT_AFFINITY"), 64); err == nil {
+		FeedWeights.Affinity = weight
+	}
+
+	if weight, err := strconv.ParseFloat(os.Getenv("FEED_WEIGHT_SECOND_DEGREE"), 64); err == nil {
+		FeedWeights.SecondDegree = weight
+	}
 }
@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"api.devbook/src/timeline"
+	"github.com/gorilla/mux"
+)
+
+// BlockUser bloqueia um usuário, ocultando mutuamente os perfis e as publicações
+func BlockUser(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	blockedID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if id == blockedID {
+		response.Error(w, http.StatusForbidden, errors.New("Não é permitido bloquear a si mesmo"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	following, followedBy, err := repo.Block(id, blockedID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// As linhas do tempo só mudam quando havia uma relação de seguidor entre os dois
+	if following {
+		if err = timeline.OnUnfollow(db, id, blockedID); err != nil {
+			log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+		}
+	}
+
+	if followedBy {
+		if err = timeline.OnUnfollow(db, blockedID, id); err != nil {
+			log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+		}
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// UnblockUser remove o bloqueio de um usuário
+func UnblockUser(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	blockedID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	if err = repo.Unblock(id, blockedID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// GetBlockedUsers retorna os usuários bloqueados pelo usuário autenticado
+func GetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível ver os bloqueios de outro usuário"))
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	blocked, next, err := repo.GetAllBlocked(id, page)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	admin, err := repo.IsAdmin(id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, model.ProjectUsers(blocked, id, admin), next)
+}
+
+// MuteUser silencia um usuário, ocultando as suas publicações do feed sem que ele saiba
+func MuteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	mutedID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if id == mutedID {
+		response.Error(w, http.StatusForbidden, errors.New("Não é permitido silenciar a si mesmo"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	if err = repo.Mute(id, mutedID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// UnmuteUser deixa de silenciar um usuário
+func UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	mutedID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	if err = repo.Unmute(id, mutedID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// GetMutedUsers retorna os usuários silenciados pelo usuário autenticado
+func GetMutedUsers(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível ver os usuários silenciados por outro usuário"))
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	muted, next, err := repo.GetAllMuted(id, page)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	admin, err := repo.IsAdmin(id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, model.ProjectUsers(muted, id, admin), next)
+}
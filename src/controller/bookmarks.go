@@ -0,0 +1,411 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errCollectionNotFound = errors.New("Coleção não encontrada")
+	errCollectionExists   = errors.New("Você já possui uma coleção com este nome")
+)
+
+// BookmarkPublication salva a publicação para o usuário. O corpo da requisição pode informar o
+// collectionId da coleção em que ela será guardada; salvar novamente move a publicação de coleção
+func BookmarkPublication(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var bookmark struct {
+		CollectionID uint64 `json:"collectionId"`
+	}
+
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, &bookmark); err != nil {
+			response.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if _, err = findVisiblePublication(db, userID, publicationID); err != nil {
+		if errors.Is(err, errPublicationNotFound) {
+			response.Error(w, http.StatusNotFound, err)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	repo := repository.NewRepositoryOfBookmarks(db)
+
+	if bookmark.CollectionID != 0 {
+		if _, err = repo.GetCollection(userID, bookmark.CollectionID); err != nil {
+			respondCollectionError(w, err)
+			return
+		}
+	}
+
+	if err = repo.Save(userID, publicationID, bookmark.CollectionID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// UnbookmarkPublication retira a publicação das salvas pelo usuário
+func UnbookmarkPublication(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if err = repository.NewRepositoryOfBookmarks(db).Remove(userID, publicationID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// GetBookmarks retorna uma página das publicações salvas pelo usuário, visível apenas para ele. O
+// parâmetro collection filtra pelo id de uma coleção
+func GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível ver as publicações salvas por outro usuário"))
+		return
+	}
+
+	var collectionID uint64
+	if collection := r.URL.Query().Get("collection"); collection != "" {
+		if collectionID, err = strconv.ParseUint(collection, 10, 64); err != nil {
+			response.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfBookmarks(db)
+
+	if collectionID != 0 {
+		if _, err = repo.GetCollection(id, collectionID); err != nil {
+			respondCollectionError(w, err)
+			return
+		}
+	}
+
+	publications, next, err := repo.GetAll(id, collectionID, page)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, publications, next)
+}
+
+// CreateCollection cria uma coleção de publicações salvas para o usuário
+func CreateCollection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível criar coleções para outro usuário"))
+		return
+	}
+
+	collection, err := collectionFromBody(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	collection.UserID = id
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfBookmarks(db)
+
+	exists, err := repo.CollectionNameExists(id, 0, collection.Name)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if exists {
+		response.Error(w, http.StatusConflict, errCollectionExists)
+		return
+	}
+
+	collection.ID, err = repo.CreateCollection(collection)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, collection)
+}
+
+// GetCollections retorna as coleções de publicações salvas do usuário, visíveis apenas para ele
+func GetCollections(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível ver as coleções de outro usuário"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	collections, err := repository.NewRepositoryOfBookmarks(db).GetAllCollections(id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, collections)
+}
+
+// UpdateCollection renomeia uma coleção do usuário
+func UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	collectionID, err := strconv.ParseUint(params["collectionId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível atualizar as coleções de outro usuário"))
+		return
+	}
+
+	collection, err := collectionFromBody(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfBookmarks(db)
+
+	if _, err = repo.GetCollection(id, collectionID); err != nil {
+		respondCollectionError(w, err)
+		return
+	}
+
+	exists, err := repo.CollectionNameExists(id, collectionID, collection.Name)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if exists {
+		response.Error(w, http.StatusConflict, errCollectionExists)
+		return
+	}
+
+	if err = repo.RenameCollection(id, collectionID, collection.Name); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// DeleteCollection exclui uma coleção do usuário, mantendo salvas as publicações que estavam nela
+func DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	collectionID, err := strconv.ParseUint(params["collectionId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível excluir as coleções de outro usuário"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfBookmarks(db)
+
+	if _, err = repo.GetCollection(id, collectionID); err != nil {
+		respondCollectionError(w, err)
+		return
+	}
+
+	if err = repo.DeleteCollection(id, collectionID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// collectionFromBody lê e valida a coleção enviada no corpo da requisição
+func collectionFromBody(r *http.Request) (model.Collection, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return model.Collection{}, err
+	}
+
+	var collection model.Collection
+	if err = json.Unmarshal(body, &collection); err != nil {
+		return model.Collection{}, err
+	}
+
+	if err = collection.Prepare(); err != nil {
+		return model.Collection{}, err
+	}
+
+	return collection, nil
+}
+
+// respondCollectionError responde com o status adequado ao erro da busca de uma coleção
+func respondCollectionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, errCollectionNotFound)
+		return
+	}
+
+	response.Error(w, http.StatusInternalServerError, err)
+}
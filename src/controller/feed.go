@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/config"
+	"api.devbook/src/database"
+	"api.devbook/src/pagination"
+	"api.devbook/src/ranking"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"api.devbook/src/timeline"
+)
+
+const (
+	// rankingWindow é o período em que as publicações são consideradas para o feed por relevância
+	rankingWindow = 7 * 24 * time.Hour
+
+	// maxRankingCandidates limita a quantidade de publicações avaliadas para o feed por relevância
+	maxRankingCandidates = 500
+)
+
+// GetFeed retorna o feed do usuário no modo informado pelo parâmetro mode: "chronological", o padrão,
+// com a linha do tempo, ou "ranked", com as publicações dos usuários seguidos e dos seguidos por eles
+// ordenadas por relevância
+func GetFeed(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != "chronological" && mode != "ranked" {
+		response.Error(w, http.StatusBadRequest, errors.New("O parâmetro mode deve ser chronological ou ranked"))
+		return
+	}
+
+	readPage := pagination.FromRequest
+	if mode == "ranked" {
+		readPage = pagination.RankedFromRequest
+	}
+
+	page, err := readPage(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if mode != "ranked" {
+		publications, next, err := timeline.Read(db, id, page)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Page(w, r, publications, next)
+		return
+	}
+
+	now := time.Now()
+
+	repo := repository.NewRepositoryOfPublications(db)
+	candidates, err := repo.GetRankingCandidates(id, now.Add(-rankingWindow), maxRankingCandidates)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ranked, next := pagination.Slice(ranking.Rank(candidates, config.FeedWeights, now), page)
+
+	publicationIDs := make([]uint64, 0, len(ranked))
+	for _, candidate := range ranked {
+		publicationIDs = append(publicationIDs, candidate.PublicationID)
+	}
+
+	publications, err := repo.GetByIDs(id, publicationIDs)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, publications, next)
+}
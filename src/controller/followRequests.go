@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"api.devbook/src/timeline"
+	"github.com/gorilla/mux"
+)
+
+var errFollowRequestNotFound = errors.New("Solicitação para seguir não encontrada")
+
+// GetFollowRequests retorna as solicitações pendentes para seguir o usuário autenticado
+func GetFollowRequests(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível ver as solicitações de outro usuário"))
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	requesters, next, err := repo.GetAllFollowRequests(id, page)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	admin, err := repo.IsAdmin(id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, model.ProjectUsers(requesters, id, admin), next)
+}
+
+// ApproveFollowRequest aprova uma solicitação pendente para seguir o usuário autenticado
+func ApproveFollowRequest(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	requesterID, err := strconv.ParseUint(params["requesterId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível aprovar solicitações de outro usuário"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	if err = repo.ApproveFollowRequest(id, requesterID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, errFollowRequestNotFound)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = timeline.OnFollow(db, requesterID, id); err != nil {
+		log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// RejectFollowRequest recusa uma solicitação pendente para seguir o usuário autenticado
+func RejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	requesterID, err := strconv.ParseUint(params["requesterId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível recusar solicitações de outro usuário"))
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	if err = repo.RejectFollowRequest(id, requesterID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, errFollowRequestNotFound)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
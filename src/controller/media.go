@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/config"
+	"api.devbook/src/database"
+	"api.devbook/src/media"
+	"api.devbook/src/model"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"api.devbook/src/storage"
+	"github.com/gorilla/mux"
+)
+
+// errInvalidProfileImage é retornado quando a imagem de perfil informada não é uma mídia enviada pelo
+// usuário com a finalidade correspondente
+var errInvalidProfileImage = errors.New("A imagem de perfil deve ser o id ou a chave de uma imagem enviada por você com essa finalidade")
+
+// UploadMedia recebe uma imagem em um formulário multipart, no campo "file", e a armazena. Quando a
+// finalidade é avatar ou header, o perfil do usuário passa a referenciar a nova imagem e a anterior é
+// excluída
+func UploadMedia(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	// A folga acomoda os demais campos e os delimitadores do formulário
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxUploadSize+(1<<20))
+	if err = r.ParseMultipartForm(config.MaxUploadSize); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(w, http.StatusRequestEntityTooLarge, errUploadTooLarge())
+			return
+		}
+
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	uploaded := model.Media{Purpose: r.FormValue("purpose")}
+	if uploaded.Purpose == "" {
+		uploaded.Purpose = model.MediaAttachment
+	}
+
+	if err = uploaded.ValidatePurpose(); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, errors.New("O arquivo deve ser enviado no campo file"))
+		return
+	}
+	defer file.Close()
+
+	if header.Size > config.MaxUploadSize {
+		response.Error(w, http.StatusRequestEntityTooLarge, errUploadTooLarge())
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, config.MaxUploadSize))
+	if err != nil {
+		response.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	stored, err := media.Store(ownerID, uploaded.Purpose, data)
+	if err != nil {
+		if errors.Is(err, media.ErrUnsupportedType) || errors.Is(err, media.ErrTooLarge) {
+			response.Error(w, http.StatusUnsupportedMediaType, err)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		media.Remove(stored.Key)
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfMedia(db)
+	stored.ID, err = repo.Create(stored)
+	if err != nil {
+		media.Remove(stored.Key)
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if stored.Purpose == model.MediaAvatar || stored.Purpose == model.MediaHeader {
+		usersRepo := repository.NewRepositoryOfUsers(db)
+		if err = usersRepo.UpdateProfileImage(ownerID, stored.Purpose, stored.URL); err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		removeReplacedImages(db, ownerID, stored.Purpose, stored.ID)
+	}
+
+	response.JSON(w, http.StatusCreated, stored)
+}
+
+// ServeMedia entrega um arquivo armazenado. Como as chaves são geradas a cada envio e os arquivos
+// nunca são alterados, a resposta pode ser mantida em cache indefinidamente
+func ServeMedia(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	object, err := storage.Default.Open(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			response.Error(w, http.StatusNotFound, err)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer object.Close()
+
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, key, object.ModTime, object)
+}
+
+// resolveProfileImage troca a referência informada para a imagem de perfil, que deve ser o id ou a
+// chave de uma mídia enviada pelo usuário com a finalidade indicada, pelo endereço público dela e
+// retorna o id da mídia. Uma referência vazia remove a imagem e retorna zero
+func resolveProfileImage(db *sql.DB, ownerID uint64, purpose string, reference *string) (uint64, error) {
+	if *reference == "" {
+		return 0, nil
+	}
+
+	image, err := repository.NewRepositoryOfMedia(db).GetProfileImage(ownerID, purpose, *reference)
+	if err != nil {
+		return 0, err
+	}
+
+	if image.ID == 0 {
+		return 0, fmt.Errorf("%w: %s", errInvalidProfileImage, purpose)
+	}
+
+	media.FillURLs(&image)
+	*reference = image.URL
+
+	return image.ID, nil
+}
+
+// removeReplacedImages exclui as imagens de perfil do usuário com a finalidade informada que não são a
+// atual, junto com os seus arquivos. Os erros são registrados no log, pois o perfil já foi atualizado
+func removeReplacedImages(db *sql.DB, ownerID uint64, purpose string, currentID uint64) {
+	repo := repository.NewRepositoryOfMedia(db)
+
+	replaced, err := repo.GetReplacedProfileImages(ownerID, purpose, currentID)
+	if err != nil {
+		log.Printf("Erro ao buscar as imagens de perfil substituídas: %v", err)
+		return
+	}
+
+	for _, image := range replaced {
+		if err = repo.Delete(image.ID); err != nil {
+			log.Printf("Erro ao excluir a mídia %d: %v", image.ID, err)
+			continue
+		}
+
+		if err = media.Remove(image.Key); err != nil {
+			log.Printf("Erro ao remover a mídia %d: %v", image.ID, err)
+		}
+	}
+}
+
+func errUploadTooLarge() error {
+	return fmt.Errorf("O arquivo deve ter no máximo %d bytes", config.MaxUploadSize)
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/pagination"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"github.com/gorilla/mux"
+)
+
+var errNotificationNotFound = errors.New("Notificação não encontrada")
+
+// GetNotifications retorna uma página das notificações do usuário autenticado. Com o parâmetro
+// unread=true, apenas as não lidas são retornadas
+func GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfNotifications(db)
+	notifications, next, err := repo.GetAll(userID, unreadOnly, page)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, notifications, next)
+}
+
+// MarkNotificationAsRead marca uma notificação do usuário autenticado como lida
+func MarkNotificationAsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	notificationID, err := strconv.ParseUint(mux.Vars(r)["notificationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfNotifications(db)
+	if err = repo.MarkAsRead(userID, notificationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, errNotificationNotFound)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// MarkAllNotificationsAsRead marca todas as notificações do usuário autenticado como lidas
+func MarkAllNotificationsAsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if err = repository.NewRepositoryOfNotifications(db).MarkAllAsRead(userID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
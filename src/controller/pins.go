@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"github.com/gorilla/mux"
+)
+
+// PinPublication fixa uma publicação do usuário no perfil dele
+func PinPublication(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfPublications(db)
+
+	publicationInDB, err := repo.GetById(publicationID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if publicationInDB.ID == 0 {
+		response.Error(w, http.StatusNotFound, errPublicationNotFound)
+		return
+	}
+
+	if publicationInDB.AuthorID != id {
+		response.Error(w, http.StatusForbidden, errors.New("Você não pode fixar uma publicação que não pertence à você"))
+		return
+	}
+
+	if err = repo.Pin(id, publicationID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotPinnable):
+			response.Error(w, http.StatusBadRequest, err)
+		case errors.Is(err, repository.ErrTooManyPinned):
+			response.Error(w, http.StatusConflict, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// UnpinPublication desafixa uma publicação do perfil do usuário
+func UnpinPublication(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfPublications(db)
+
+	publicationInDB, err := repo.GetById(publicationID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if publicationInDB.ID == 0 {
+		response.Error(w, http.StatusNotFound, errPublicationNotFound)
+		return
+	}
+
+	if publicationInDB.AuthorID != id {
+		response.Error(w, http.StatusForbidden, errors.New("Você não pode desafixar uma publicação que não pertence à você"))
+		return
+	}
+
+	if err = repo.Unpin(publicationID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"github.com/gorilla/mux"
+)
+
+var errPollNotFound = errors.New("Esta publicação não tem enquete")
+
+// VoteOnPoll registra o voto do usuário na enquete da publicação e retorna a enquete com os resultados
+func VoteOnPoll(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var vote model.Vote
+	if err = json.Unmarshal(body, &vote); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = vote.Validate(); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	publication, err := findVisiblePublication(db, userID, publicationID)
+	if err != nil {
+		if errors.Is(err, errPublicationNotFound) {
+			response.Error(w, http.StatusNotFound, err)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// O voto em um repost vale para a enquete da publicação original
+	if publication.RepostOf != nil {
+		publication = *publication.RepostOf
+	}
+
+	repo := repository.NewRepositoryOfPublications(db).WithViewer(userID)
+	if err = repo.Vote(publication.ID, userID, vote.OptionIDs); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.Error(w, http.StatusNotFound, errPollNotFound)
+		case errors.Is(err, repository.ErrInvalidVote):
+			response.Error(w, http.StatusBadRequest, err)
+		case errors.Is(err, repository.ErrPollClosed), errors.Is(err, repository.ErrAlreadyVoted),
+			errors.Is(err, repository.ErrPollNotPublished):
+			response.Error(w, http.StatusConflict, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	publication, err = repo.GetById(publication.ID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, publication.Poll)
+}
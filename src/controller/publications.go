@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"io"
@@ -9,12 +10,22 @@ import (
 
 	"api.devbook/src/auth"
 	"api.devbook/src/database"
+	"api.devbook/src/media"
 	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/publishing"
 	"api.devbook/src/repository"
 	"api.devbook/src/response"
+	"api.devbook/src/text"
+	"api.devbook/src/timeline"
 	"github.com/gorilla/mux"
 )
 
+var (
+	errPublicationNotFound = errors.New("Publicação não encontrada")
+	errInvalidAttachment   = errors.New("Uma das mídias anexadas não existe, não pertence a você ou já está em outra publicação")
+)
+
 // CreatePublication adiciona uma nova publicação no banco de dados
 func CreatePublication(w http.ResponseWriter, r *http.Request) {
 	id, err := auth.ExtractUserID(r)
@@ -36,6 +47,9 @@ func CreatePublication(w http.ResponseWriter, r *http.Request) {
 	}
 
 	publication.AuthorID = id
+	// Reposts são criados apenas por RepostPublication
+	publication.RepostOfID = 0
+	inheritThread(&publication)
 
 	if err = publication.Prepare(); err != nil {
 		response.Error(w, http.StatusBadRequest, err)
@@ -49,19 +63,58 @@ func CreatePublication(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
-	repo := repository.NewRepositoryOfPublications(db)
+	if err = resolveNewPublication(db, id, &publication); err != nil {
+		respondNewPublicationError(w, err)
+		return
+	}
+
+	for i := range publication.Thread {
+		if err = resolveNewPublication(db, id, &publication.Thread[i]); err != nil {
+			respondNewPublicationError(w, err)
+			return
+		}
+	}
+
+	repo := repository.NewRepositoryOfPublications(db).WithViewer(id)
 	publicationID, err := repo.Create(publication)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	publication.ID = publicationID
+	if len(publication.Thread) == 0 {
+		publication, err = repo.GetById(publicationID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Rascunhos e publicações agendadas só são distribuídos quando forem publicados
+		if publication.Status == model.StatusPublished {
+			publishing.OnPublish(db, publication)
+		}
+
+		response.JSON(w, http.StatusCreated, publication)
+		return
+	}
+
+	thread, err := repo.GetThread(publicationID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, part := range thread {
+		publishing.OnPublish(db, part)
+	}
+
+	publication = thread[0]
+	publication.Thread = thread[1:]
 
 	response.JSON(w, http.StatusCreated, publication)
 }
 
-// GetAllPublications traz as publicações dos usuários seguidos, dos seguidores e as próprias publicações
+// GetAllPublications traz a linha do tempo do usuário: as próprias publicações e as dos usuários seguidos
 func GetAllPublications(w http.ResponseWriter, r *http.Request) {
 	id, err := auth.ExtractUserID(r)
 	if err != nil {
@@ -69,6 +122,12 @@ func GetAllPublications(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
 	db, err := database.Connect()
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
@@ -76,18 +135,23 @@ func GetAllPublications(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
-	repo := repository.NewRepositoryOfPublications(db)
-	publications, err := repo.GetAll(id)
+	publications, next, err := timeline.Read(db, id, page)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	response.JSON(w, http.StatusOK, publications)
+	response.Page(w, r, publications, next)
 }
 
 // GetPublication traz a publicação com base no id fornecido
 func GetPublication(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 
 	publicationID, err := strconv.ParseUint(params["publicationId"], 10, 64)
@@ -103,9 +167,14 @@ func GetPublication(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
-	repo := repository.NewRepositoryOfPublications(db)
-	publication, err := repo.GetById(publicationID)
+	// Quem não pode ver a publicação recebe 404, sem saber se ela existe
+	publication, err := findVisiblePublication(db, viewerID, publicationID)
 	if err != nil {
+		if errors.Is(err, errPublicationNotFound) {
+			response.Error(w, http.StatusNotFound, err)
+			return
+		}
+
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -144,11 +213,21 @@ func UpdatePublication(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if publicationInDB.ID == 0 {
+		response.Error(w, http.StatusNotFound, errPublicationNotFound)
+		return
+	}
+
 	if publicationInDB.AuthorID != id {
 		response.Error(w, http.StatusForbidden, errors.New("Você não pode editar uma publicação que não pertence à você"))
 		return
 	}
 
+	if publicationInDB.RepostOfID != 0 {
+		response.Error(w, http.StatusBadRequest, errors.New("Reposts não podem ser editados"))
+		return
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		response.Error(w, http.StatusBadRequest, err)
@@ -161,13 +240,45 @@ func UpdatePublication(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Sem uma nova situação, rascunhos e publicações agendadas continuam como estão
+	if publication.Status == "" {
+		publication.Status = publicationInDB.Status
+		if publication.PublishAt == nil {
+			publication.PublishAt = publicationInDB.PublishAt
+		}
+	}
+
+	// As partes seguintes de uma thread têm a visibilidade da primeira
+	if publication.Visibility == "" || publicationInDB.ThreadID != 0 {
+		publication.Visibility = publicationInDB.Visibility
+	}
+
+	// A expiração é definida na criação
+	publication.ExpiresAt = publicationInDB.ExpiresAt
+
+	// As partes da thread são criadas junto com a primeira ou por ContinueThread
+	publication.Thread = nil
+
+	// A enquete é definida na criação e não pode ser alterada, para não invalidar os votos
+	publication.Poll = nil
+
 	if err = publication.Prepare(); err != nil {
 		response.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if err = repo.Update(publicationID, publication); err != nil {
-		response.Error(w, http.StatusInternalServerError, err)
+	// A enquete de um rascunho ou de uma publicação agendada foi validada com a data prevista na
+	// criação, então é validada de novo com a data em que a publicação passa a ser publicada
+	if publicationInDB.Poll != nil && publicationInDB.Status != model.StatusPublished &&
+		publication.Status != model.StatusDraft {
+		if err = publicationInDB.Poll.Validate(publication.PublishAt); err != nil {
+			response.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err = editPublication(db, publicationInDB, publication); err != nil {
+		respondEditError(w, err)
 		return
 	}
 
@@ -204,21 +315,58 @@ func DeletePublication(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if publicationInDB.ID == 0 {
+		response.Error(w, http.StatusNotFound, errPublicationNotFound)
+		return
+	}
+
 	if publicationInDB.AuthorID != id {
 		response.Error(w, http.StatusForbidden, errors.New("Você não pode excluir uma publicação que não pertence à você"))
 		return
 	}
 
+	// Excluir a primeira parte de uma thread exclui também as partes seguintes
+	deleted := []model.Publication{publicationInDB}
+	if publicationInDB.ThreadID == 0 && publicationInDB.ThreadLength > 1 {
+		if deleted, err = repo.GetThread(publicationID); err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	// Os reposts são excluídos em cascata e também saem das linhas do tempo
+	deletedIDs := make([]uint64, 0, len(deleted))
+	for _, publication := range deleted {
+		deletedIDs = append(deletedIDs, publication.ID)
+	}
+
+	reposts, err := repo.GetReposts(deletedIDs)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	if err = repo.Delete(publicationID); err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	for _, publication := range append(deleted, reposts...) {
+		publishing.OnDelete(db, publication)
+	}
+
 	response.JSON(w, http.StatusNoContent, nil)
 }
 
-// GetAllPublicationsOfUser retorna todas as publicações de um usuário
+// GetAllPublicationsOfUser retorna as publicações de um usuário, com as fixadas no início da primeira
+// página
 func GetAllPublicationsOfUser(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 
 	authorId, err := strconv.ParseUint(params["userId"], 10, 64)
@@ -227,6 +375,12 @@ func GetAllPublicationsOfUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
 	db, err := database.Connect()
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
@@ -234,21 +388,42 @@ func GetAllPublicationsOfUser(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
-	repo := repository.NewRepositoryOfPublications(db)
-	publications, err := repo.GetAllPublicationsOfUser(authorId)
+	if !checkProfileAccess(w, repository.NewRepositoryOfUsers(db), viewerID, authorId) {
+		return
+	}
+
+	repo := repository.NewRepositoryOfPublications(db).WithViewer(viewerID)
+	publications, next, err := repo.GetAllPublicationsOfUser(authorId, page)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	response.JSON(w, http.StatusOK, publications)
+	// As publicações fixadas aparecem antes das demais, apenas na primeira página
+	if page.After == nil {
+		pinned, err := repo.GetPinned(authorId)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(pinned) > 0 {
+			publications = append(pinned, publications...)
+		}
+	}
+
+	response.Page(w, r, publications, next)
 }
 
-// LikePublication incrementa em um a quantidade de curtidas de publicação
-func LikePublication(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
+// GetDrafts retorna os rascunhos e as publicações agendadas do usuário autenticado
+func GetDrafts(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
 
-	publicationId, err := strconv.ParseUint(params["publicationId"], 10, 64)
+	page, err := pagination.FromRequest(r)
 	if err != nil {
 		response.Error(w, http.StatusBadRequest, err)
 		return
@@ -262,36 +437,208 @@ func LikePublication(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewRepositoryOfPublications(db)
-	if err := repo.Like(publicationId); err != nil {
+	drafts, next, err := repo.GetDrafts(id, page)
+	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	response.JSON(w, http.StatusNoContent, nil)
+	response.Page(w, r, drafts, next)
 }
 
-// LikePublication decrementa em um a quantidade de curtidas de publicação
-func DislikePublication(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
+// resolveAttachments verifica se as mídias podem ser anexadas à publicação pelo autor e preenche os
+// dados de cada mídia nos anexos
+func resolveAttachments(db *sql.DB, authorID, publicationID uint64, attachments []model.Attachment) error {
+	mediaIDs := make([]uint64, 0, len(attachments))
+	for _, attachment := range attachments {
+		mediaIDs = append(mediaIDs, attachment.MediaID)
+	}
 
-	publicationId, err := strconv.ParseUint(params["publicationId"], 10, 64)
+	repo := repository.NewRepositoryOfMedia(db)
+	attachable, err := repo.GetAttachable(authorID, publicationID, mediaIDs)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, err)
-		return
+		return err
 	}
 
-	db, err := database.Connect()
+	for i, attachment := range attachments {
+		attachedMedia, ok := attachable[attachment.MediaID]
+		if !ok {
+			return errInvalidAttachment
+		}
+
+		media.FillURLs(&attachedMedia)
+		attachments[i].Media = attachedMedia
+	}
+
+	return nil
+}
+
+// resolveMentions preenche o id do usuário de cada menção. Nicks inexistentes e usuários que possuem
+// bloqueio com o autor ficam sem id e não são gravados
+func resolveMentions(db *sql.DB, authorID uint64, mentions []model.Mention) error {
+	nicks := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		nicks = append(nicks, mention.Nick)
+	}
+
+	users, err := repository.NewRepositoryOfUsers(db).GetMentionable(authorID, nicks)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, err)
+		return err
+	}
+
+	// A comparação de nicks no banco ignora maiúsculas e acentos
+	byNick := make(map[string]uint64, len(users))
+	for _, user := range users {
+		byNick[text.Fold(user.Nick)] = user.ID
+	}
+
+	for i, mention := range mentions {
+		mentions[i].UserID = byNick[text.Fold(mention.Nick)]
+	}
+
+	return nil
+}
+
+// resolveNewPublication verifica a publicação citada e os anexos de uma nova publicação do autor e
+// resolve as suas menções
+func resolveNewPublication(db *sql.DB, authorID uint64, publication *model.Publication) error {
+	if publication.QuotedID != 0 {
+		quoted, err := originalFor(db, authorID, publication.QuotedID)
+		if err != nil {
+			return err
+		}
+
+		publication.QuotedID = quoted.ID
+	}
+
+	if err := resolveAttachments(db, authorID, 0, publication.Attachments); err != nil {
+		return err
+	}
+
+	return resolveMentions(db, authorID, publication.Mentions)
+}
+
+// respondNewPublicationError responde com o status adequado ao erro retornado por
+// resolveNewPublication
+func respondNewPublicationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errInvalidAttachment) {
+		response.Error(w, http.StatusBadRequest, err)
 		return
 	}
-	defer db.Close()
+
+	respondOriginalError(w, err)
+}
+
+// editPublication grava a nova versão já preparada de uma publicação do autor e executa os efeitos da
+// edição, ou da publicação quando um rascunho ou uma publicação agendada é publicado
+func editPublication(db *sql.DB, publicationInDB, publication model.Publication) error {
+	authorID := publicationInDB.AuthorID
+
+	if err := resolveAttachments(db, authorID, publicationInDB.ID, publication.Attachments); err != nil {
+		return err
+	}
+
+	if err := resolveMentions(db, authorID, publication.Mentions); err != nil {
+		return err
+	}
 
 	repo := repository.NewRepositoryOfPublications(db)
-	if err := repo.Dislike(publicationId); err != nil {
+	published, err := repo.Update(publicationInDB.ID, publication)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case published:
+		// A data de criação e a publicação citada são lidas do banco de dados
+		publication, err = repo.GetById(publicationInDB.ID)
+		if err != nil {
+			return err
+		}
+
+		publishing.OnPublish(db, publication)
+	case publication.Status == model.StatusPublished:
+		publication.ID = publicationInDB.ID
+		publication.AuthorID = authorID
+		publication.ThreadID = publicationInDB.ThreadID
+		publication.CreatedAt = publicationInDB.CreatedAt
+
+		publishing.OnEdit(db, publication, publicationInDB)
+	}
+
+	return nil
+}
+
+// respondEditError responde com o status adequado ao erro retornado por editPublication
+func respondEditError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errInvalidAttachment):
+		response.Error(w, http.StatusBadRequest, err)
+	case errors.Is(err, repository.ErrAlreadyPublished):
+		response.Error(w, http.StatusConflict, err)
+	default:
 		response.Error(w, http.StatusInternalServerError, err)
-		return
 	}
+}
 
-	response.JSON(w, http.StatusNoContent, nil)
+// findVisiblePublication retorna a publicação caso ela exista e o usuário possa vê-la, ou
+// errPublicationNotFound caso contrário
+func findVisiblePublication(db *sql.DB, viewerID, publicationID uint64) (model.Publication, error) {
+	publication, err := repository.NewRepositoryOfPublications(db).WithViewer(viewerID).GetById(publicationID)
+	if err != nil {
+		return model.Publication{}, err
+	}
+
+	if publication.ID == 0 {
+		return model.Publication{}, errPublicationNotFound
+	}
+
+	visible, err := canSeePublication(db, viewerID, publication)
+	if err != nil {
+		return model.Publication{}, err
+	}
+
+	if !visible {
+		return model.Publication{}, errPublicationNotFound
+	}
+
+	return publication, nil
+}
+
+// canSeePublication verifica se o usuário pode ver a publicação: rascunhos e publicações agendadas
+// só são vistos pelo autor, e as demais dependem de bloqueios, da privacidade da conta do autor e da
+// visibilidade da publicação
+func canSeePublication(db *sql.DB, viewerID uint64, publication model.Publication) (bool, error) {
+	if publication.AuthorID == viewerID {
+		return true, nil
+	}
+
+	if publication.Status != model.StatusPublished {
+		return false, nil
+	}
+
+	usersRepo := repository.NewRepositoryOfUsers(db)
+
+	blocked, err := usersRepo.IsBlocked(viewerID, publication.AuthorID)
+	if err != nil || blocked {
+		return false, err
+	}
+
+	canView, err := usersRepo.CanView(viewerID, publication.AuthorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil || !canView {
+		return false, err
+	}
+
+	following := false
+	if publication.Visibility == model.VisibilityFollowers {
+		if following, err = usersRepo.IsFollowing(viewerID, publication.AuthorID); err != nil {
+			return false, err
+		}
+	}
+
+	return publication.VisibleTo(viewerID, following), nil
 }
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"github.com/gorilla/mux"
+)
+
+// ReactToPublication registra a reação do usuário na publicação, substituindo a anterior
+func ReactToPublication(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var reaction model.Reaction
+	if err = json.Unmarshal(body, &reaction); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = reaction.Prepare(); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	setReaction(w, r, reaction.Type)
+}
+
+// RemoveReaction remove a reação do usuário na publicação
+func RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	setReaction(w, r, "")
+}
+
+// LikePublication registra a reação like do usuário na publicação. Mantida por compatibilidade com
+// as curtidas
+func LikePublication(w http.ResponseWriter, r *http.Request) {
+	setReaction(w, r, model.ReactionLike)
+}
+
+// DislikePublication remove a reação do usuário na publicação. Mantida por compatibilidade com as
+// curtidas
+func DislikePublication(w http.ResponseWriter, r *http.Request) {
+	setReaction(w, r, "")
+}
+
+// setReaction grava a reação do usuário autenticado na publicação da rota, ou a remove quando a
+// reação está vazia
+func setReaction(w http.ResponseWriter, r *http.Request, reaction string) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfPublications(db)
+
+	if reaction == "" {
+		if err = repo.Unreact(publicationID, userID); err != nil {
+			respondReactionError(w, err)
+			return
+		}
+
+		response.JSON(w, http.StatusNoContent, nil)
+		return
+	}
+
+	if _, err = findVisiblePublication(db, userID, publicationID); err != nil {
+		if errors.Is(err, errPublicationNotFound) {
+			response.Error(w, http.StatusNotFound, err)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// A publicação pode ter sido excluída depois da verificação acima
+	if err = repo.React(publicationID, userID, reaction); err != nil {
+		respondReactionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// respondReactionError responde 404 quando a publicação da reação não existe e 500 nos demais erros
+func respondReactionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		response.Error(w, http.StatusNotFound, errPublicationNotFound)
+		return
+	}
+
+	response.Error(w, http.StatusInternalServerError, err)
+}
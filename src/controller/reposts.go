@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"api.devbook/src/timeline"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errPrivateOriginal = errors.New("Publicações de contas privadas ou que não são públicas não podem ser repostadas ou citadas")
+	errRepostNotFound  = errors.New("Você não repostou esta publicação")
+)
+
+// RepostPublication reposta a publicação para os seguidores do usuário, atribuindo o repost a ele e
+// exibindo a publicação original. Repostar um repost reposta a publicação original
+func RepostPublication(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	original, err := originalFor(db, userID, publicationID)
+	if err != nil {
+		respondOriginalError(w, err)
+		return
+	}
+
+	repo := repository.NewRepositoryOfPublications(db).WithViewer(userID)
+
+	repostID, err := repo.GetRepostID(userID, original.ID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if repostID != 0 {
+		response.Error(w, http.StatusConflict, repository.ErrAlreadyReposted)
+		return
+	}
+
+	repostID, err = repo.Create(model.Publication{AuthorID: userID, RepostOfID: original.ID})
+	if errors.Is(err, repository.ErrAlreadyReposted) {
+		response.Error(w, http.StatusConflict, err)
+		return
+	}
+
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	repost, err := repo.GetById(repostID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = timeline.OnPublish(db, repost); err != nil {
+		log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+	}
+
+	if err = repository.NewRepositoryOfNotifications(db).Create(model.Notification{
+		Type:          model.NotificationRepost,
+		ActorID:       userID,
+		PublicationID: original.ID,
+	}, original.AuthorID); err != nil {
+		log.Printf("Erro ao notificar o autor da publicação repostada: %v", err)
+	}
+
+	response.JSON(w, http.StatusCreated, repost)
+}
+
+// UnrepostPublication desfaz o repost da publicação feito pelo usuário
+func UnrepostPublication(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfPublications(db)
+
+	repostID, err := repo.GetRepostID(userID, publicationID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if repostID == 0 {
+		response.Error(w, http.StatusNotFound, errRepostNotFound)
+		return
+	}
+
+	if err = repo.Delete(repostID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = timeline.OnDelete(db, model.Publication{ID: repostID, AuthorID: userID}); err != nil {
+		log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// originalFor retorna a publicação que será repostada ou citada pelo usuário, substituindo reposts
+// pela publicação original. Apenas publicações públicas, visíveis para o usuário e de contas públicas,
+// ou do próprio usuário, podem ser repostadas ou citadas
+func originalFor(db *sql.DB, userID, publicationID uint64) (model.Publication, error) {
+	original, err := repository.NewRepositoryOfPublications(db).WithViewer(userID).GetById(publicationID)
+	if err != nil {
+		return model.Publication{}, err
+	}
+
+	// Rascunhos e publicações agendadas não podem ser repostados nem citados, nem pelo próprio autor
+	if original.ID == 0 || original.Status != model.StatusPublished {
+		return model.Publication{}, errPublicationNotFound
+	}
+
+	if original.RepostOf != nil {
+		original = *original.RepostOf
+	}
+
+	if original.AuthorID == userID {
+		return original, nil
+	}
+
+	visible, err := canSeePublication(db, userID, original)
+	if err != nil {
+		return model.Publication{}, err
+	}
+
+	if !visible {
+		return model.Publication{}, errPublicationNotFound
+	}
+
+	// Repostar ou citar levaria a publicação a quem a visibilidade não permite
+	if original.Visibility != model.VisibilityPublic {
+		return model.Publication{}, errPrivateOriginal
+	}
+
+	private, err := repository.NewRepositoryOfUsers(db).IsPrivate(original.AuthorID)
+	if err != nil {
+		return model.Publication{}, err
+	}
+
+	if private {
+		return model.Publication{}, errPrivateOriginal
+	}
+
+	return original, nil
+}
+
+// respondOriginalError responde com o status adequado ao erro retornado por originalFor
+func respondOriginalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errPublicationNotFound):
+		response.Error(w, http.StatusNotFound, err)
+	case errors.Is(err, errPrivateOriginal):
+		response.Error(w, http.StatusForbidden, err)
+	default:
+		response.Error(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"github.com/gorilla/mux"
+)
+
+var errRevisionNotFound = errors.New("Revisão não encontrada")
+
+// GetRevisions retorna o histórico de edições de uma publicação que o usuário pode ver
+func GetRevisions(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publicationID, err := strconv.ParseUint(params["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if _, err = findVisiblePublication(db, viewerID, publicationID); err != nil {
+		if errors.Is(err, errPublicationNotFound) {
+			response.Error(w, http.StatusNotFound, err)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	repo := repository.NewRepositoryOfPublications(db)
+	revisions, next, err := repo.GetRevisions(publicationID, page)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, revisions, next)
+}
+
+// RestoreRevision volta o título e o conteúdo da publicação para os de uma versão anterior. A
+// restauração é uma nova edição, de modo que a versão substituída também fica no histórico
+func RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	publicationID, err := strconv.ParseUint(params["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	revisionID, err := strconv.ParseUint(params["revisionId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfPublications(db)
+
+	publicationInDB, err := repo.GetById(publicationID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if publicationInDB.ID == 0 {
+		response.Error(w, http.StatusNotFound, errPublicationNotFound)
+		return
+	}
+
+	if publicationInDB.AuthorID != id {
+		response.Error(w, http.StatusForbidden, errors.New("Você não pode editar uma publicação que não pertence à você"))
+		return
+	}
+
+	revision, err := repo.GetRevision(publicationID, revisionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, errRevisionNotFound)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	publication := model.Publication{
+		Title:       revision.Title,
+		Content:     revision.Content,
+		Attachments: publicationInDB.Attachments,
+		Status:      publicationInDB.Status,
+		Visibility:  publicationInDB.Visibility,
+		ExpiresAt:   publicationInDB.ExpiresAt,
+	}
+
+	if err = publication.Prepare(); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = editPublication(db, publicationInDB, publication); err != nil {
+		respondEditError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/markdown"
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"api.devbook/src/search"
+)
+
+// snippetLength é o tamanho máximo, em bytes, do trecho exibido em cada resultado da busca
+const snippetLength = 160
+
+// SearchPublications busca publicações pelo parâmetro q, ordenadas por relevância. Palavras entre
+// aspas são buscadas como frase e palavras terminadas em asterisco como prefixo. Os resultados podem
+// ser filtrados pelo autor, com o id ou o nick em author, e pela data, com from e to no formato
+// AAAA-MM-DD
+func SearchPublications(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := r.URL.Query()
+
+	query, err := search.ParseQuery(params.Get("q"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if query.From, err = parseSearchDate(params.Get("from")); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if query.To, err = parseSearchDate(params.Get("to")); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// A data final é inclusiva
+	if !query.To.IsZero() {
+		query.To = query.To.AddDate(0, 0, 1)
+	}
+
+	page, err := pagination.RankedFromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if author := params.Get("author"); author != "" {
+		if query.AuthorID, err = strconv.ParseUint(author, 10, 64); err != nil {
+			query.AuthorID, err = repository.NewRepositoryOfUsers(db).GetIDByNick(author)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			if query.AuthorID == 0 {
+				response.Page(w, r, []model.SearchResult{}, "")
+				return
+			}
+		}
+	}
+
+	query.ViewerID = viewerID
+
+	hits, err := search.Default.Search(query, search.MaxResults)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	hits, next := pagination.Slice(hits, page)
+
+	publicationIDs := make([]uint64, 0, len(hits))
+	scores := make(map[uint64]float64, len(hits))
+	for _, hit := range hits {
+		publicationIDs = append(publicationIDs, hit.PublicationID)
+		scores[hit.PublicationID] = hit.Score
+	}
+
+	publications, err := repository.NewRepositoryOfPublications(db).GetByIDs(viewerID, publicationIDs)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	results := make([]model.SearchResult, 0, len(publications))
+	for _, publication := range publications {
+		results = append(results, model.SearchResult{
+			Publication: publication,
+			Score:       scores[publication.ID],
+			Snippet:     search.Highlight(markdown.PlainText(publication.Content), query, snippetLength),
+		})
+	}
+
+	response.Page(w, r, results, next)
+}
+
+// parseSearchDate interpreta uma data no formato AAAA-MM-DD, retornando a data zero quando vazia
+func parseSearchDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("As datas da busca devem estar no formato AAAA-MM-DD")
+	}
+
+	return date, nil
+}
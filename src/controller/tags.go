@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/ranking"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"api.devbook/src/timeline"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// trendingWindow é o período em que o uso das hashtags é considerado para as hashtags em alta
+	trendingWindow = 24 * time.Hour
+
+	// trendingHalfLife é o tempo após o qual o peso de um uso de hashtag cai pela metade
+	trendingHalfLife = 6 * time.Hour
+
+	// maxTrendingTags é a quantidade de hashtags em alta retornadas
+	maxTrendingTags = 10
+)
+
+var errInvalidTag = errors.New("Hashtag inválida")
+
+// GetPublicationsOfTag retorna uma página das publicações com a hashtag, das mais recentes para as
+// mais antigas
+func GetPublicationsOfTag(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	tag, err := tagFromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	tagIDs, err := repository.NewRepositoryOfTags(db).GetIDsByNames([]string{tag})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if len(tagIDs) == 0 {
+		response.Page(w, r, []model.Publication{}, "")
+		return
+	}
+
+	repo := repository.NewRepositoryOfPublications(db)
+	publications, next, err := repo.GetAllPublicationsOfTag(tagIDs[0], viewerID, page)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, publications, next)
+}
+
+// FollowTag faz o usuário seguir a hashtag, passando a receber as publicações com ela no feed
+func FollowTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	tag, err := tagFromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfTags(db)
+
+	tagID, err := repo.GetOrCreate(tag)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = repo.Follow(userID, tagID); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = timeline.OnFollowTag(db, userID, tagID); err != nil {
+		log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// UnfollowTag faz o usuário deixar de seguir a hashtag
+func UnfollowTag(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	tag, err := tagFromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfTags(db)
+
+	tagIDs, err := repo.GetIDsByNames([]string{tag})
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, tagID := range tagIDs {
+		if err = repo.Unfollow(userID, tagID); err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err = timeline.OnUnfollowTag(db, userID, tagID); err != nil {
+			log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+		}
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// GetFollowedTags retorna uma página das hashtags seguidas pelo usuário autenticado
+func GetFollowedTags(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	tags, next, err := repository.NewRepositoryOfTags(db).GetAllFollowed(userID, page)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, tags, next)
+}
+
+// GetTrendingTags retorna as hashtags em alta, pontuadas pela quantidade de autores que as usaram nas
+// últimas horas, com os usos mais recentes valendo mais
+func GetTrendingTags(w http.ResponseWriter, r *http.Request) {
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	now := time.Now()
+
+	usages, err := repository.NewRepositoryOfTags(db).GetUsage(now.Add(-trendingWindow))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	scores := ranking.Trending(usages, trendingHalfLife, now, maxTrendingTags)
+
+	trending := make([]model.TrendingTag, 0, len(scores))
+	for _, score := range scores {
+		trending = append(trending, model.TrendingTag{Name: score.Tag, Score: score.Score})
+	}
+
+	response.JSON(w, http.StatusOK, trending)
+}
+
+// tagFromRequest retorna a hashtag informada na rota, normalizada
+func tagFromRequest(r *http.Request) (string, error) {
+	tag := model.NormalizeTag(mux.Vars(r)["tag"])
+
+	if tags := model.ParseTags("#" + tag); len(tags) != 1 || tags[0] != tag {
+		return "", errInvalidTag
+	}
+
+	return tag, nil
+}
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"api.devbook/src/auth"
+	"api.devbook/src/database"
+	"api.devbook/src/model"
+	"api.devbook/src/publishing"
+	"api.devbook/src/repository"
+	"api.devbook/src/response"
+	"github.com/gorilla/mux"
+)
+
+// GetThread retorna, em ordem, as partes da thread a que a publicação pertence que o usuário pode ver.
+// Uma publicação sem continuação é retornada sozinha, e o repost de uma thread retorna a thread original
+func GetThread(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	// Quem não pode ver a publicação pedida recebe 404. As demais partes são filtradas uma a uma, pois
+	// as restritas aos mencionados dependem das menções de cada parte
+	publication, err := findVisiblePublication(db, viewerID, publicationID)
+	if err != nil {
+		if errors.Is(err, errPublicationNotFound) {
+			response.Error(w, http.StatusNotFound, err)
+			return
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if publication.RepostOf != nil {
+		publication = *publication.RepostOf
+	}
+
+	threadID := publication.ThreadID
+	if threadID == 0 {
+		threadID = publication.ID
+	}
+
+	thread, err := repository.NewRepositoryOfPublications(db).WithViewer(viewerID).GetThread(threadID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Rascunhos e publicações agendadas, vistos apenas pelo autor, não têm partes publicadas
+	if len(thread) == 0 {
+		thread = []model.Publication{publication}
+	}
+
+	response.JSON(w, http.StatusOK, thread)
+}
+
+// ContinueThread responde a uma publicação do próprio usuário com a próxima parte da thread dela. A
+// nova parte tem a visibilidade da thread e é publicada imediatamente
+func ContinueThread(w http.ResponseWriter, r *http.Request) {
+	id, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	publicationID, err := strconv.ParseUint(mux.Vars(r)["publicationId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var part model.Publication
+	if err = json.Unmarshal(body, &part); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfPublications(db).WithViewer(id)
+
+	publicationInDB, err := repo.GetById(publicationID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if publicationInDB.ID == 0 {
+		response.Error(w, http.StatusNotFound, errPublicationNotFound)
+		return
+	}
+
+	if publicationInDB.AuthorID != id {
+		response.Error(w, http.StatusForbidden, errors.New("Você não pode continuar uma thread que não pertence à você"))
+		return
+	}
+
+	if part.Status != "" && part.Status != model.StatusPublished {
+		response.Error(w, http.StatusBadRequest, errors.New("As partes de uma thread são publicadas imediatamente"))
+		return
+	}
+
+	// Uma parte não pode trazer outras partes
+	part.Thread = nil
+
+	head := publicationInDB
+	head.Thread = []model.Publication{part}
+	inheritThread(&head)
+	part = head.Thread[0]
+
+	if err = part.Prepare(); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = resolveNewPublication(db, id, &part); err != nil {
+		respondNewPublicationError(w, err)
+		return
+	}
+
+	partID, err := repo.ContinueThread(publicationID, part)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotThreadable):
+			response.Error(w, http.StatusBadRequest, err)
+		case errors.Is(err, repository.ErrNotLastPart), errors.Is(err, repository.ErrThreadTooLong):
+			response.Error(w, http.StatusConflict, err)
+		case errors.Is(err, sql.ErrNoRows):
+			response.Error(w, http.StatusNotFound, errPublicationNotFound)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	part, err = repo.GetById(partID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	publishing.OnPublish(db, part)
+
+	response.JSON(w, http.StatusCreated, part)
+}
+
+// inheritThread prepara as partes seguintes da thread da publicação: elas pertencem ao mesmo autor,
+// têm a mesma situação, a mesma visibilidade e a mesma expiração e, sem um título próprio, usam o da
+// primeira parte
+func inheritThread(publication *model.Publication) {
+	publication.ThreadID = 0
+	publication.ThreadPosition = 0
+
+	for i := range publication.Thread {
+		part := &publication.Thread[i]
+		part.AuthorID = publication.AuthorID
+		part.RepostOfID = 0
+		part.Status = publication.Status
+		part.PublishAt = publication.PublishAt
+		part.Visibility = publication.Visibility
+		part.ExpiresAt = publication.ExpiresAt
+
+		if part.Title == "" {
+			part.Title = publication.Title
+		}
+	}
+}
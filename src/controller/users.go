@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,12 +13,28 @@ import (
 	"api.devbook/src/auth"
 	"api.devbook/src/database"
 	"api.devbook/src/model"
+	"api.devbook/src/pagination"
 	"api.devbook/src/repository"
 	"api.devbook/src/response"
+	"api.devbook/src/search"
 	"api.devbook/src/security"
+	"api.devbook/src/timeline"
 	"github.com/gorilla/mux"
 )
 
+const (
+	// maxSuggestions é a quantidade de usuários retornados nas sugestões enquanto se digita
+	maxSuggestions = 10
+
+	// suggestionCandidates é a quantidade de usuários avaliados para montar as sugestões
+	suggestionCandidates = 50
+)
+
+var (
+	errUserNotFound   = errors.New("Usuário não encontrado")
+	errPrivateAccount = errors.New("Esta conta é privada")
+)
+
 // Recebe a requisição para criar um usuário
 func CreateUser(w http.ResponseWriter, r *http.Request) {
 	requestBody, err := io.ReadAll(r.Body)
@@ -50,12 +68,30 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.JSON(w, http.StatusCreated, user)
+	response.JSON(w, http.StatusCreated, user.Owner())
 }
 
-// Busca todos os usuários
+// Busca os usuários pelo nome ou nick informado no parâmetro search, ordenados pela correspondência
+// com o termo. Sem o parâmetro, traz todos os usuários dos mais recentes para os mais antigos
 func GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	nameOrNick := strings.ToLower(r.URL.Query().Get("search"))
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	term := strings.TrimSpace(r.URL.Query().Get("search"))
+
+	readPage := pagination.FromRequest
+	if term != "" {
+		readPage = pagination.RankedFromRequest
+	}
+
+	page, err := readPage(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
 
 	db, err := database.Connect()
 	if err != nil {
@@ -65,13 +101,73 @@ func GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewRepositoryOfUsers(db)
-	users, err := repo.GetAll(nameOrNick)
+
+	var (
+		users []model.User
+		next  string
+	)
+
+	if term == "" {
+		users, next, err = repo.GetAll(viewerID, page)
+	} else {
+		var candidates []search.UserCandidate
+		candidates, err = repo.GetSearchCandidates(term, viewerID, search.MaxResults)
+		users, next = pagination.Slice(search.RankUsers(term, candidates, true), page)
+	}
+
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	admin, err := repo.IsAdmin(viewerID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, model.ProjectUsers(users, viewerID, admin), next)
+}
+
+// SuggestUsers retorna os usuários cujo nick ou nome começam pelo parâmetro prefix, para sugestões
+// enquanto se digita. Apenas o id, o nick, o nome e o avatar são retornados
+func SuggestUsers(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	prefix := strings.TrimSpace(r.URL.Query().Get("prefix"))
+	if prefix == "" {
+		response.JSON(w, http.StatusOK, []model.UserSuggestion{})
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	candidates, err := repository.NewRepositoryOfUsers(db).GetSuggestionCandidates(prefix, viewerID, suggestionCandidates)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	response.JSON(w, http.StatusOK, users)
+	users := search.RankUsers(prefix, candidates, false)
+	if len(users) > maxSuggestions {
+		users = users[:maxSuggestions]
+	}
+
+	suggestions := make([]model.UserSuggestion, 0, len(users))
+	for _, user := range users {
+		suggestions = append(suggestions, user.Suggestion())
+	}
+
+	response.JSON(w, http.StatusOK, suggestions)
 }
 
 // Busca um usuário
@@ -84,6 +180,12 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	db, err := database.Connect()
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
@@ -92,13 +194,161 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewRepositoryOfUsers(db)
+
+	blocked, err := repo.IsBlocked(viewerID, id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if blocked {
+		response.Error(w, http.StatusNotFound, errUserNotFound)
+		return
+	}
+
 	user, err := repo.GetByID(id)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	response.JSON(w, http.StatusOK, user)
+	if user.ID == 0 {
+		response.Error(w, http.StatusNotFound, errUserNotFound)
+		return
+	}
+
+	counts, err := repo.GetCounts(id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	user.Counts = &counts
+
+	admin, err := repo.IsAdmin(viewerID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	projected := user.ProjectFor(viewerID, admin)
+
+	if projected.Birthday == "" && user.BirthdayVisibility == model.BirthdayFollowers {
+		following, err := repo.IsFollowing(viewerID, id)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if following {
+			projected.Birthday = user.Birthday
+		}
+	}
+
+	response.JSON(w, http.StatusOK, projected)
+}
+
+// UpdateProfile atualiza parcialmente os campos do perfil de um usuário. O avatar e a capa são
+// informados pelo id ou pela chave de uma imagem enviada pelo usuário, ou vazios para removê-los
+func UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	idOfToken, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if id != idOfToken {
+		response.Error(w, http.StatusForbidden, errors.New("Não é possível atualizar o perfil de outro usuário fora o seu"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfUsers(db)
+	user, err := repo.GetByID(id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	previous := user
+
+	// Os campos ausentes no corpo da requisição mantêm os valores atuais
+	if err = json.Unmarshal(body, &user); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = user.Prepare("profile"); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// As imagens alteradas devem ter sido enviadas pelo próprio usuário com a finalidade correspondente
+	images := []struct {
+		purpose   string
+		reference *string
+		changed   bool
+		currentID uint64
+	}{
+		{purpose: model.MediaAvatar, reference: &user.Avatar, changed: user.Avatar != previous.Avatar},
+		{purpose: model.MediaHeader, reference: &user.Header, changed: user.Header != previous.Header},
+	}
+
+	for i, image := range images {
+		if !image.changed {
+			continue
+		}
+
+		if images[i].currentID, err = resolveProfileImage(db, id, image.purpose, image.reference); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, errInvalidProfileImage) {
+				status = http.StatusBadRequest
+			}
+
+			response.Error(w, status, err)
+			return
+		}
+	}
+
+	if err = repo.UpdateProfile(id, user); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, image := range images {
+		if image.changed {
+			removeReplacedImages(db, id, image.purpose, image.currentID)
+		}
+	}
+
+	// O perfil é lido novamente para que a resposta contenha apenas o que foi gravado
+	user, err = repo.GetByID(id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, user.Owner())
 }
 
 // Atualiza um usuário
@@ -134,6 +384,16 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Um campo ausente seria lido como false e tornaria a conta pública, aprovando as solicitações
+	// pendentes, então a privacidade só muda quando é enviada
+	var privacy struct {
+		Private *bool `json:"private"`
+	}
+	if err = json.Unmarshal(body, &privacy); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
 	if err = user.Prepare("edit"); err != nil {
 		response.Error(w, http.StatusBadRequest, err)
 		return
@@ -147,11 +407,27 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewRepositoryOfUsers(db)
-	if err = repo.Update(id, user); err != nil {
+
+	if privacy.Private == nil {
+		if user.Private, err = repo.IsPrivate(id); err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	approvedIDs, err := repo.Update(id, user)
+	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	// As solicitações aprovadas ao tornar a conta pública são novos seguidores
+	for _, followerID := range approvedIDs {
+		if err = timeline.OnFollow(db, followerID, id); err != nil {
+			log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+		}
+	}
+
 	response.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -193,7 +469,8 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusNoContent, nil)
 }
 
-// FollowUser permite que um usuário siga outro
+// FollowUser permite que um usuário siga outro. Caso a conta seja privada, é criada uma solicitação
+// que precisa ser aprovada pelo dono da conta
 func FollowUser(w http.ResponseWriter, r *http.Request) {
 	id, err := auth.ExtractUserID(r)
 	if err != nil {
@@ -221,11 +498,51 @@ func FollowUser(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewRepositoryOfUsers(db)
+
+	blocked, err := repo.IsBlocked(id, followedID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if blocked {
+		response.Error(w, http.StatusForbidden, errors.New("Não é permitido seguir este usuário"))
+		return
+	}
+
+	private, err := repo.IsPrivate(followedID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if private {
+		following, err := repo.IsFollowing(id, followedID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !following {
+			if err = repo.RequestFollow(id, followedID); err != nil {
+				response.Error(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			response.JSON(w, http.StatusAccepted, nil)
+			return
+		}
+	}
+
 	if err = repo.Follow(id, followedID); err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	if err = timeline.OnFollow(db, id, followedID); err != nil {
+		log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+	}
+
 	response.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -262,6 +579,10 @@ func UnfollowUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = timeline.OnUnfollow(db, id, followedID); err != nil {
+		log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+	}
+
 	response.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -274,6 +595,18 @@ func GetFollowers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
 	db, err := database.Connect()
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
@@ -282,13 +615,24 @@ func GetFollowers(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewRepositoryOfUsers(db)
-	followers, err := repo.GetAllFollowers(id)
+
+	if !checkProfileAccess(w, repo, viewerID, id) {
+		return
+	}
+
+	followers, next, err := repo.GetAllFollowers(id, viewerID, page)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	response.JSON(w, http.StatusOK, followers)
+	admin, err := repo.IsAdmin(viewerID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, model.ProjectUsers(followers, viewerID, admin), next)
 }
 
 // GetFollowing busca todos os usuários que um usuário está seguindo
@@ -300,6 +644,18 @@ func GetFollowing(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID, err := auth.ExtractUserID(r)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
 	db, err := database.Connect()
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
@@ -308,13 +664,24 @@ func GetFollowing(w http.ResponseWriter, r *http.Request) {
 	defer db.Close()
 
 	repo := repository.NewRepositoryOfUsers(db)
-	following, err := repo.GetAllFollowing(id)
+
+	if !checkProfileAccess(w, repo, viewerID, id) {
+		return
+	}
+
+	following, next, err := repo.GetAllFollowing(id, viewerID, page)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	response.JSON(w, http.StatusOK, following)
+	admin, err := repo.IsAdmin(viewerID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.Page(w, r, model.ProjectUsers(following, viewerID, admin), next)
 }
 
 // UpdatePassword atualiza a senha no banco de dados
@@ -381,3 +748,37 @@ func UpdatePassword(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, http.StatusNoContent, nil)
 }
+
+// checkProfileAccess verifica se o usuário pode ver o conteúdo do perfil de outro, respondendo 404
+// quando o usuário não existe ou há bloqueio entre eles e 403 quando a conta é privada e ele não é
+// seguidor. Retorna falso quando a resposta já foi enviada
+func checkProfileAccess(w http.ResponseWriter, repo *repository.Users, viewerID, id uint64) bool {
+	blocked, err := repo.IsBlocked(viewerID, id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	if blocked {
+		response.Error(w, http.StatusNotFound, errUserNotFound)
+		return false
+	}
+
+	canView, err := repo.CanView(viewerID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, http.StatusNotFound, errUserNotFound)
+			return false
+		}
+
+		response.Error(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	if !canView {
+		response.Error(w, http.StatusForbidden, errPrivateAccount)
+		return false
+	}
+
+	return true
+}
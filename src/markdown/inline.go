@@ -0,0 +1,248 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+type inlineKind int
+
+const (
+	text inlineKind = iota
+	code
+	emphasis
+	strong
+	link
+)
+
+// inline é um trecho de uma linha. Textos e códigos guardam o conteúdo em text, enquanto ênfases,
+// negritos e links guardam os trechos internos em children. Links com endereços não aceitos ficam com
+// url vazia e são exibidos apenas como texto
+type inline struct {
+	kind     inlineKind
+	text     string
+	url      string
+	children []inline
+}
+
+// parseInline separa a linha em trechos. Dentro do texto de um link não são aceitos outros links
+func parseInline(line string, links bool) []inline {
+	runes := []rune(line)
+
+	var (
+		nodes []inline
+		plain []rune
+	)
+
+	for i := 0; i < len(runes); {
+		var (
+			node inline
+			next int
+			ok   bool
+		)
+
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes) && isEscapable(runes[i+1]):
+			plain = append(plain, runes[i+1])
+			i += 2
+			continue
+		case r == '`':
+			node, next, ok = parseCode(runes, i)
+		case r == '[' && links:
+			node, next, ok = parseLink(runes, i)
+		case r == '*' || r == '_':
+			node, next, ok = parseEmphasis(runes, i, links)
+		}
+
+		if !ok {
+			plain = append(plain, runes[i])
+			i++
+			continue
+		}
+
+		if len(plain) > 0 {
+			nodes = append(nodes, inline{kind: text, text: string(plain)})
+			plain = nil
+		}
+
+		nodes = append(nodes, node)
+		i = next
+	}
+
+	if len(plain) > 0 {
+		nodes = append(nodes, inline{kind: text, text: string(plain)})
+	}
+
+	return nodes
+}
+
+func parseCode(runes []rune, start int) (inline, int, bool) {
+	for end := start + 1; end < len(runes); end++ {
+		if runes[end] == '`' {
+			if end == start+1 {
+				return inline{}, 0, false
+			}
+
+			return inline{kind: code, text: string(runes[start+1 : end])}, end + 1, true
+		}
+	}
+
+	return inline{}, 0, false
+}
+
+func parseLink(runes []rune, start int) (inline, int, bool) {
+	closing := indexRune(runes, start+1, ']')
+	if closing <= start+1 || closing+1 >= len(runes) || runes[closing+1] != '(' {
+		return inline{}, 0, false
+	}
+
+	// Parênteses equilibrados fazem parte do endereço, como em páginas da Wikipédia
+	end, depth := -1, 0
+	for i := closing + 2; i < len(runes) && end < 0; i++ {
+		switch runes[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				end = i
+			}
+			depth--
+		}
+	}
+
+	if end < 0 {
+		return inline{}, 0, false
+	}
+
+	address := strings.TrimSpace(string(runes[closing+2 : end]))
+	if !isSafeURL(address) {
+		address = ""
+	}
+
+	return inline{
+		kind:     link,
+		url:      address,
+		children: parseInline(string(runes[start+1:closing]), false),
+	}, end + 1, true
+}
+
+// parseEmphasis reconhece ênfases e negritos. O delimitador de abertura não pode ser seguido de
+// espaço e o de fechamento não pode ser precedido de espaço. O _ não é aceito no meio de palavras,
+// para não afetar nomes como snake_case
+func parseEmphasis(runes []rune, start int, links bool) (inline, int, bool) {
+	delimiter := runes[start]
+
+	size := 1
+	if start+1 < len(runes) && runes[start+1] == delimiter {
+		size = 2
+	}
+
+	open := start + size
+	if open >= len(runes) || unicode.IsSpace(runes[open]) {
+		return inline{}, 0, false
+	}
+
+	if delimiter == '_' && start > 0 && isWordRune(runes[start-1]) {
+		return inline{}, 0, false
+	}
+
+	for end := open + 1; end+size <= len(runes); end++ {
+		if !hasDelimiter(runes, end, delimiter, size) || unicode.IsSpace(runes[end-1]) {
+			continue
+		}
+
+		after := end + size
+		if after < len(runes) && runes[after] == delimiter {
+			continue
+		}
+
+		if delimiter == '_' && after < len(runes) && isWordRune(runes[after]) {
+			continue
+		}
+
+		kind := emphasis
+		if size == 2 {
+			kind = strong
+		}
+
+		return inline{kind: kind, children: parseInline(string(runes[open:end]), links)}, after, true
+	}
+
+	return inline{}, 0, false
+}
+
+func hasDelimiter(runes []rune, at int, delimiter rune, size int) bool {
+	for i := at; i < at+size; i++ {
+		if runes[i] != delimiter {
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeHTML(builder *strings.Builder, nodes []inline) {
+	for _, node := range nodes {
+		switch node.kind {
+		case text:
+			builder.WriteString(html.EscapeString(node.text))
+		case code:
+			builder.WriteString("<code>" + html.EscapeString(node.text) + "</code>")
+		case emphasis:
+			builder.WriteString("<em>")
+			writeHTML(builder, node.children)
+			builder.WriteString("</em>")
+		case strong:
+			builder.WriteString("<strong>")
+			writeHTML(builder, node.children)
+			builder.WriteString("</strong>")
+		case link:
+			if node.url == "" {
+				writeHTML(builder, node.children)
+				continue
+			}
+
+			builder.WriteString(`<a href="` + html.EscapeString(node.url) + `" rel="nofollow ugc">`)
+			writeHTML(builder, node.children)
+			builder.WriteString("</a>")
+		}
+	}
+}
+
+// isSafeURL aceita apenas endereços http e https com domínio e endereços mailto, impedindo esquemas
+// como javascript:
+func isSafeURL(address string) bool {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return parsed.Host != ""
+	case "mailto":
+		return parsed.Opaque != ""
+	}
+
+	return false
+}
+
+func indexRune(runes []rune, from int, target rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func isEscapable(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsPunct(r) || unicode.IsSymbol(r))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,211 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	bulletItem  = regexp.MustCompile(`^ {0,3}[-*+] +(.*)$`)
+	orderedItem = regexp.MustCompile(`^ {0,3}\d{1,9}[.)] +(.*)$`)
+	codeFence   = regexp.MustCompile("^ {0,3}```")
+)
+
+type blockKind int
+
+const (
+	paragraph blockKind = iota
+	bulletList
+	orderedList
+	codeBlock
+)
+
+// block é um bloco do texto. Nos parágrafos, lines são as linhas; nas listas, os itens; e nos blocos
+// de código, as linhas do código
+type block struct {
+	kind  blockKind
+	lines []string
+}
+
+// ToHTML converte o texto, escrito no subconjunto de Markdown aceito nas publicações, em HTML seguro.
+// São aceitos parágrafos, listas com e sem numeração, blocos de código cercados por ```, ênfase com *
+// ou _, negrito com ** ou __, código com ` e links no formato [texto](url). Qualquer HTML do texto é
+// escapado, de modo que a renderização nunca contém scripts. Os links recebem rel="nofollow ugc" e
+// apenas endereços http, https e mailto são aceitos
+func ToHTML(source string) string {
+	var builder strings.Builder
+
+	for i, block := range parseBlocks(source) {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		switch block.kind {
+		case paragraph:
+			builder.WriteString("<p>")
+			for j, line := range block.lines {
+				if j > 0 {
+					builder.WriteString("<br>\n")
+				}
+				writeHTML(&builder, parseInline(line, true))
+			}
+			builder.WriteString("</p>")
+		case bulletList, orderedList:
+			tag := "ul"
+			if block.kind == orderedList {
+				tag = "ol"
+			}
+
+			builder.WriteString("<" + tag + ">")
+			for _, item := range block.lines {
+				builder.WriteString("<li>")
+				writeHTML(&builder, parseInline(item, true))
+				builder.WriteString("</li>")
+			}
+			builder.WriteString("</" + tag + ">")
+		case codeBlock:
+			builder.WriteString("<pre><code>")
+			builder.WriteString(html.EscapeString(strings.Join(block.lines, "\n")))
+			builder.WriteString("</code></pre>")
+		}
+	}
+
+	return builder.String()
+}
+
+// PlainText retorna o texto visível, sem a marcação. É usado para limitar o tamanho das publicações e
+// para indexá-las na busca
+func PlainText(source string) string {
+	return ParseText(source).Plain
+}
+
+// Text é o texto visível de um conteúdo junto com a indicação de quais caracteres pertencem ao texto
+// comum. Códigos, blocos de código e links, incluindo o texto deles, não são texto comum
+type Text struct {
+	Plain string
+	prose []bool
+}
+
+// ParseText retorna o texto visível do conteúdo, o mesmo de PlainText, marcando os caracteres de
+// texto comum. Menções e hashtags são procuradas apenas nesses caracteres
+func ParseText(source string) Text {
+	var builder textBuilder
+
+	for i, block := range parseBlocks(source) {
+		if i > 0 {
+			builder.write("\n\n", false)
+		}
+
+		for j, line := range block.lines {
+			if j > 0 {
+				builder.write("\n", false)
+			}
+
+			if block.kind == codeBlock {
+				builder.write(line, false)
+			} else {
+				builder.writeInline(parseInline(line, true), true)
+			}
+		}
+	}
+
+	return Text{Plain: string(builder.runes), prose: builder.prose}
+}
+
+// IsProse informa se todos os caracteres de start até end, exclusive, pertencem ao texto comum
+func (t Text) IsProse(start, end int) bool {
+	if start < 0 || end > len(t.prose) || start >= end {
+		return false
+	}
+
+	for _, prose := range t.prose[start:end] {
+		if !prose {
+			return false
+		}
+	}
+
+	return true
+}
+
+// textBuilder monta o texto visível caractere a caractere, guardando se cada um é texto comum
+type textBuilder struct {
+	runes []rune
+	prose []bool
+}
+
+func (b *textBuilder) write(value string, prose bool) {
+	for _, r := range value {
+		b.runes = append(b.runes, r)
+		b.prose = append(b.prose, prose)
+	}
+}
+
+func (b *textBuilder) writeInline(nodes []inline, prose bool) {
+	for _, node := range nodes {
+		switch node.kind {
+		case text:
+			b.write(node.text, prose)
+		case code:
+			b.write(node.text, false)
+		case link:
+			b.writeInline(node.children, false)
+		default:
+			b.writeInline(node.children, prose)
+		}
+	}
+}
+
+func parseBlocks(source string) []block {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	var (
+		blocks  []block
+		current *block
+	)
+
+	flush := func() {
+		if current != nil {
+			blocks = append(blocks, *current)
+			current = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case codeFence.MatchString(line):
+			flush()
+
+			code := block{kind: codeBlock}
+			for i++; i < len(lines) && !codeFence.MatchString(lines[i]); i++ {
+				code.lines = append(code.lines, lines[i])
+			}
+			blocks = append(blocks, code)
+		case bulletItem.MatchString(line):
+			if current == nil || current.kind != bulletList {
+				flush()
+				current = &block{kind: bulletList}
+			}
+			current.lines = append(current.lines, bulletItem.FindStringSubmatch(line)[1])
+		case orderedItem.MatchString(line):
+			if current == nil || current.kind != orderedList {
+				flush()
+				current = &block{kind: orderedList}
+			}
+			current.lines = append(current.lines, orderedItem.FindStringSubmatch(line)[1])
+		default:
+			if current == nil || current.kind != paragraph {
+				flush()
+				current = &block{kind: paragraph}
+			}
+			current.lines = append(current.lines, strings.TrimSpace(line))
+		}
+	}
+
+	flush()
+	return blocks
+}
@@ -0,0 +1,69 @@
+package markdown
+
+import "testing"
+
+func TestParseText(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		plain  string
+		// prose marca com "x" os caracteres de texto comum de plain
+		prose string
+	}{
+		{
+			name:   "texto comum",
+			source: "oi @alice",
+			plain:  "oi @alice",
+			prose:  "xxxxxxxxx",
+		},
+		{
+			name:   "ênfase e negrito",
+			source: "*a* **b**",
+			plain:  "a b",
+			prose:  "xxx",
+		},
+		{
+			name:   "código",
+			source: "a `@b` c",
+			plain:  "a @b c",
+			prose:  "xx__xx",
+		},
+		{
+			name:   "link",
+			source: "[@a](https://x.com/@b) c",
+			plain:  "@a c",
+			prose:  "__xx",
+		},
+		{
+			name:   "bloco de código",
+			source: "```\n#x\n```\nfim",
+			plain:  "#x\n\nfim",
+			prose:  "____xxx",
+		},
+		{
+			name:   "lista",
+			source: "- a\n- b",
+			plain:  "a\nb",
+			prose:  "x_x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseText(tt.source)
+			if got.Plain != tt.plain {
+				t.Fatalf("Plain = %q, want %q", got.Plain, tt.plain)
+			}
+
+			if PlainText(tt.source) != tt.plain {
+				t.Errorf("PlainText = %q, want %q", PlainText(tt.source), tt.plain)
+			}
+
+			for i, mark := range []rune(tt.prose) {
+				if want := mark == 'x'; got.IsProse(i, i+1) != want {
+					t.Errorf("IsProse(%d, %d) = %v, want %v", i, i+1, !want, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // Registra o decodificador de GIF
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// ThumbnailSizes são os tamanhos, em pixels, do maior lado das miniaturas geradas para cada imagem
+var ThumbnailSizes = []int{150, 600}
+
+// maxPixels limita a resolução das imagens aceitas, evitando que imagens pequenas em bytes
+// consumam memória demais ao serem decodificadas
+const maxPixels = 40_000_000
+
+var (
+	// ErrUnsupportedType é retornado quando o arquivo não é uma imagem JPEG, PNG ou GIF
+	ErrUnsupportedType = errors.New("O arquivo deve ser uma imagem JPEG, PNG ou GIF")
+
+	// ErrTooLarge é retornado quando a resolução da imagem excede o limite permitido
+	ErrTooLarge = errors.New("A resolução da imagem excede o limite permitido")
+)
+
+// Image representa uma imagem reprocessada, pronta para ser armazenada
+type Image struct {
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+	Data        []byte
+	Thumbnails  map[int][]byte
+}
+
+// Process identifica o tipo da imagem pelo conteúdo, decodifica e codifica novamente, descartando
+// metadados como o EXIF, e gera as miniaturas nos tamanhos de ThumbnailSizes. Imagens PNG e GIF
+// são convertidas para PNG para preservar a transparência, as demais para JPEG
+func Process(data []byte) (*Image, error) {
+	var encode func(*bytes.Buffer, image.Image) error
+
+	processed := &Image{Thumbnails: make(map[int][]byte)}
+
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		processed.ContentType, processed.Extension = "image/jpeg", ".jpg"
+		encode = func(buf *bytes.Buffer, img image.Image) error {
+			return jpeg.Encode(buf, img, &jpeg.Options{Quality: 85})
+		}
+	case "image/png", "image/gif":
+		processed.ContentType, processed.Extension = "image/png", ".png"
+		encode = func(buf *bytes.Buffer, img image.Image) error {
+			return png.Encode(buf, img)
+		}
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+
+	processed.Width, processed.Height = img.Bounds().Dx(), img.Bounds().Dy()
+
+	var buf bytes.Buffer
+	if err = encode(&buf, img); err != nil {
+		return nil, err
+	}
+	processed.Data = buf.Bytes()
+
+	for _, size := range ThumbnailSizes {
+		var thumbnail bytes.Buffer
+		if err = encode(&thumbnail, fit(img, size)); err != nil {
+			return nil, err
+		}
+
+		processed.Thumbnails[size] = thumbnail.Bytes()
+	}
+
+	return processed, nil
+}
+
+// fit reduz a imagem para que o maior lado tenha no máximo size pixels, mantendo a proporção.
+// Imagens menores que o tamanho informado não são ampliadas
+func fit(img image.Image, size int) image.Image {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width >= height {
+		height = atLeast(1, height*size/width)
+		width = size
+	} else {
+		width = atLeast(1, width*size/height)
+		height = size
+	}
+
+	return resize(img, width, height)
+}
+
+// resize redimensiona a imagem calculando a média da área de origem de cada pixel de destino
+func resize(src image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/height
+		y1 := atLeast(y0+1, bounds.Min.Y+(y+1)*srcHeight/height)
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcWidth/width
+			x1 := atLeast(x0+1, bounds.Min.X+(x+1)*srcWidth/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
+
+func atLeast(minimum, value int) int {
+	if value < minimum {
+		return minimum
+	}
+
+	return value
+}
@@ -0,0 +1,86 @@
+package media
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"api.devbook/src/model"
+	"api.devbook/src/storage"
+)
+
+// Store processa a imagem e grava o arquivo e as miniaturas no armazenamento padrão, retornando
+// os dados da mídia prontos para serem salvos no banco de dados
+func Store(ownerID uint64, purpose string, data []byte) (model.Media, error) {
+	img, err := Process(data)
+	if err != nil {
+		return model.Media{}, err
+	}
+
+	key, err := newKey(img.Extension)
+	if err != nil {
+		return model.Media{}, err
+	}
+
+	if err = storage.Default.Save(key, bytes.NewReader(img.Data)); err != nil {
+		return model.Media{}, err
+	}
+
+	for size, thumbnail := range img.Thumbnails {
+		if err = storage.Default.Save(thumbnailKey(key, size), bytes.NewReader(thumbnail)); err != nil {
+			Remove(key)
+			return model.Media{}, err
+		}
+	}
+
+	stored := model.Media{
+		OwnerID:     ownerID,
+		Purpose:     purpose,
+		Key:         key,
+		ContentType: img.ContentType,
+		Width:       img.Width,
+		Height:      img.Height,
+		Size:        int64(len(img.Data)),
+	}
+	FillURLs(&stored)
+
+	return stored, nil
+}
+
+// Remove exclui do armazenamento padrão o arquivo e as miniaturas da chave informada
+func Remove(key string) error {
+	for _, size := range ThumbnailSizes {
+		if err := storage.Default.Delete(thumbnailKey(key, size)); err != nil {
+			return err
+		}
+	}
+
+	return storage.Default.Delete(key)
+}
+
+// FillURLs preenche os endereços públicos do arquivo e das miniaturas da mídia
+func FillURLs(m *model.Media) {
+	m.URL = storage.Default.URL(m.Key)
+	m.Thumbnails = make(map[string]string, len(ThumbnailSizes))
+
+	for _, size := range ThumbnailSizes {
+		m.Thumbnails[fmt.Sprint(size)] = storage.Default.URL(thumbnailKey(m.Key, size))
+	}
+}
+
+func newKey(extension string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(random) + extension, nil
+}
+
+func thumbnailKey(key string, size int) string {
+	extension := path.Ext(key)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(key, extension), size, extension)
+}
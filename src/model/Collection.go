@@ -0,0 +1,34 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxCollectionNameLength = 50
+
+// Collection representa uma coleção nomeada em que o usuário organiza as publicações salvas
+type Collection struct {
+	ID        uint64    `json:"id,omitempty"`
+	UserID    uint64    `json:"userId,omitempty"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+// Prepare formata e valida o nome da coleção
+func (collection *Collection) Prepare() error {
+	collection.Name = strings.TrimSpace(collection.Name)
+
+	if collection.Name == "" {
+		return errors.New("O campo de nome deve ser preenchido")
+	}
+
+	if utf8.RuneCountInString(collection.Name) > maxCollectionNameLength {
+		return fmt.Errorf("O nome da coleção deve ter no máximo %d caracteres", maxCollectionNameLength)
+	}
+
+	return nil
+}
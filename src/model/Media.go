@@ -0,0 +1,38 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Finalidades possíveis de um arquivo enviado
+const (
+	MediaAvatar     = "avatar"
+	MediaHeader     = "header"
+	MediaAttachment = "attachment"
+)
+
+// Media representa uma imagem enviada por um usuário
+type Media struct {
+	ID          uint64            `json:"id,omitempty"`
+	OwnerID     uint64            `json:"ownerId,omitempty"`
+	Purpose     string            `json:"purpose,omitempty"`
+	Key         string            `json:"-"`
+	ContentType string            `json:"contentType,omitempty"`
+	Width       int               `json:"width,omitempty"`
+	Height      int               `json:"height,omitempty"`
+	Size        int64             `json:"size,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Thumbnails  map[string]string `json:"thumbnails,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt,omitempty"`
+}
+
+// ValidatePurpose verifica se a finalidade informada é conhecida
+func (m *Media) ValidatePurpose() error {
+	switch m.Purpose {
+	case MediaAvatar, MediaHeader, MediaAttachment:
+		return nil
+	}
+
+	return errors.New("A finalidade do arquivo deve ser avatar, header ou attachment")
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+
+	"api.devbook/src/markdown"
+)
+
+// MaxMentions é a quantidade máxima de usuários mencionados em uma publicação
+const MaxMentions = 10
+
+// Mention representa a menção a um usuário no conteúdo de uma publicação. Start e End são as posições,
+// em caracteres, do trecho "@nick" no texto visível do conteúdo, sem a marcação do Markdown
+type Mention struct {
+	UserID uint64 `json:"userId"`
+	Nick   string `json:"nick"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+}
+
+// ParseMentions retorna as menções "@nick" do conteúdo em Markdown na ordem em que aparecem, ainda sem
+// o id do usuário. Um nick é formado por letras, dígitos, "_", "." e "-" e não pode estar colado a uma
+// palavra anterior, o que evita confundir endereços de e-mail com menções. Menções em códigos, blocos
+// de código e links são ignoradas
+func ParseMentions(content string) []Mention {
+	var mentions []Mention
+
+	visible := markdown.ParseText(content)
+
+	runes := []rune(visible.Plain)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isNickRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isNickRune(runes[end]) {
+			end++
+		}
+
+		// Pontuação no final pertence à frase e não ao nick
+		for end > i+1 && strings.ContainsRune(".-", runes[end-1]) {
+			end--
+		}
+
+		if end > i+1 && visible.IsProse(i, end) {
+			mentions = append(mentions, Mention{Nick: string(runes[i+1 : end]), Start: i, End: end})
+		}
+
+		i = end - 1
+	}
+
+	return mentions
+}
+
+func isNickRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-", r)
+}
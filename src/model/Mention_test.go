@@ -0,0 +1,63 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Mention
+	}{
+		{
+			name:    "texto comum",
+			content: "oi @alice e @bob.",
+			want:    []Mention{{Nick: "alice", Start: 3, End: 9}, {Nick: "bob", Start: 12, End: 16}},
+		},
+		{
+			name:    "e-mail não é menção",
+			content: "fale com alice@example.com",
+		},
+		{
+			name:    "bloco de código",
+			content: "```\n@admin #secret\n```",
+		},
+		{
+			name:    "código",
+			content: "rode `@root` agora",
+		},
+		{
+			name:    "endereço de link",
+			content: "[me](https://github.com/@alice)",
+		},
+		{
+			name:    "texto de link",
+			content: "[@alice](https://example.com)",
+		},
+		{
+			name:    "posições no texto visível",
+			content: "**negrito** e `code` com @alice",
+			want:    []Mention{{Nick: "alice", Start: 19, End: 25}},
+		},
+		{
+			name:    "dentro de ênfase",
+			content: "obrigado *@alice*",
+			want:    []Mention{{Nick: "alice", Start: 9, End: 15}},
+		},
+		{
+			name:    "em lista depois de um bloco de código",
+			content: "```\n@root\n```\n\n- @bob",
+			want:    []Mention{{Nick: "bob", Start: 7, End: 11}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMentions(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
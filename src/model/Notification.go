@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Tipos de notificação
+const (
+	NotificationMention = "mention"
+	NotificationRepost  = "repost"
+	NotificationQuote   = "quote"
+)
+
+// Notification representa um aviso a um usuário sobre uma ação de outro usuário
+type Notification struct {
+	ID            uint64    `json:"id"`
+	Type          string    `json:"type"`
+	ActorID       uint64    `json:"actorId"`
+	ActorNick     string    `json:"actorNick"`
+	PublicationID uint64    `json:"publicationId,omitempty"`
+	Read          bool      `json:"read"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
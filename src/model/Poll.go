@@ -0,0 +1,112 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limites de uma enquete
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 6
+	maxPollOptionLength = 50
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// Poll representa uma enquete que faz parte de uma publicação. Os votos de cada opção só são
+// retornados depois que o usuário que está consultando votou ou quando a enquete foi encerrada
+type Poll struct {
+	Options  []PollOption `json:"options"`
+	Multiple bool         `json:"multiple"`
+	ClosesAt time.Time    `json:"closesAt"`
+	Closed   bool         `json:"closed"`
+	// Voters é a quantidade de usuários que votaram, sempre visível
+	Voters        uint64   `json:"voters"`
+	ResultsHidden bool     `json:"resultsHidden,omitempty"`
+	MyVotes       []uint64 `json:"myVotes,omitempty"`
+}
+
+// PollOption representa uma das opções de uma enquete
+type PollOption struct {
+	ID    uint64  `json:"id,omitempty"`
+	Text  string  `json:"text"`
+	Votes *uint64 `json:"votes,omitempty"`
+}
+
+// Validate verifica as opções e a data de encerramento da enquete. Em publicações agendadas, a
+// enquete deve ser encerrada depois da publicação
+func (poll *Poll) Validate(publishAt *time.Time) error {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return fmt.Errorf("A enquete deve ter de %d a %d opções", minPollOptions, maxPollOptions)
+	}
+
+	texts := make(map[string]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		text := strings.TrimSpace(option.Text)
+		if text == "" {
+			return errors.New("As opções da enquete devem ser preenchidas")
+		}
+
+		if utf8.RuneCountInString(text) > maxPollOptionLength {
+			return fmt.Errorf("As opções da enquete devem ter no máximo %d caracteres", maxPollOptionLength)
+		}
+
+		if texts[strings.ToLower(text)] {
+			return errors.New("As opções da enquete devem ser diferentes entre si")
+		}
+		texts[strings.ToLower(text)] = true
+	}
+
+	start := time.Now()
+	if publishAt != nil {
+		start = *publishAt
+	}
+
+	if !poll.ClosesAt.After(start) {
+		return errors.New("A enquete deve ser encerrada depois de publicada")
+	}
+
+	if poll.ClosesAt.Sub(start) > maxPollDuration {
+		return fmt.Errorf("A enquete deve ser encerrada em no máximo %d dias", int(maxPollDuration.Hours()/24))
+	}
+
+	return nil
+}
+
+// Format retira os espaços das extremidades das opções e descarta os dados calculados enviados pelo
+// cliente
+func (poll *Poll) Format() {
+	for i := range poll.Options {
+		poll.Options[i] = PollOption{Text: strings.TrimSpace(poll.Options[i].Text)}
+	}
+
+	poll.Closed = false
+	poll.Voters = 0
+	poll.ResultsHidden = false
+	poll.MyVotes = nil
+}
+
+// Vote representa as opções escolhidas por um usuário em uma enquete
+type Vote struct {
+	OptionIDs []uint64 `json:"optionIds"`
+}
+
+// Validate verifica se o voto escolhe ao menos uma opção, sem repetições
+func (vote Vote) Validate() error {
+	if len(vote.OptionIDs) == 0 {
+		return errors.New("O voto deve escolher ao menos uma opção")
+	}
+
+	chosen := make(map[uint64]bool, len(vote.OptionIDs))
+	for _, optionID := range vote.OptionIDs {
+		if chosen[optionID] {
+			return errors.New("A mesma opção não pode ser escolhida mais de uma vez")
+		}
+		chosen[optionID] = true
+	}
+
+	return nil
+}
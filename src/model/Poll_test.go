@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollValidateDeadline(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		value := now.Add(d)
+		return &value
+	}
+
+	tests := []struct {
+		name      string
+		closesAt  time.Time
+		publishAt *time.Time
+		wantErr   bool
+	}{
+		{name: "publicada agora", closesAt: now.Add(time.Hour)},
+		{name: "encerrada antes de agora", closesAt: now.Add(-time.Minute), wantErr: true},
+		{name: "agendada", closesAt: now.Add(3 * time.Hour), publishAt: at(2 * time.Hour)},
+		{name: "encerrada antes do agendamento", closesAt: now.Add(time.Hour), publishAt: at(2 * time.Hour), wantErr: true},
+		{name: "duração máxima", closesAt: now.Add(maxPollDuration + time.Hour), publishAt: at(2 * time.Hour)},
+		{name: "além da duração máxima", closesAt: now.Add(maxPollDuration + time.Hour), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll := Poll{Options: []PollOption{{Text: "A"}, {Text: "B"}}, ClosesAt: tt.closesAt}
+
+			if err := poll.Validate(tt.publishAt); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
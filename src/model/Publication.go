@@ -2,19 +2,103 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"api.devbook/src/markdown"
+)
+
+// Limites dos anexos de uma publicação
+const (
+	MaxAttachments   = 4
+	maxAltTextLength = 1000
+)
+
+// Limites do conteúdo de uma publicação. O conteúdo é escrito em Markdown, e o limite principal vale
+// para o texto visível, sem a marcação
+const (
+	maxContentLength       = 300
+	maxContentSourceLength = 1000
+)
+
+// MaxPinned é a quantidade máxima de publicações fixadas no perfil de um usuário
+const MaxPinned = 3
+
+// MaxThreadLength é a quantidade máxima de partes de uma thread, contando a primeira
+const MaxThreadLength = 25
+
+// Situações possíveis de uma publicação. Rascunhos e publicações agendadas são visíveis apenas
+// para o autor
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+)
+
+// Níveis de visibilidade de uma publicação: para todos que podem ver a conta do autor, apenas para os
+// seguidores, apenas para os usuários mencionados ou apenas para o autor
+const (
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityMentioned = "mentioned"
+	VisibilityPrivate   = "private"
 )
 
 // Publication representa uma publicação feita por um usuário
 type Publication struct {
-	ID         uint64    `json:"id,omitempty"`
-	Title      string    `json:"title,omitempty"`
-	Content    string    `json:"content,omitempty"`
-	AuthorID   uint64    `json:"authorId,omitempty"`
-	AuthorNick string    `json:"authorNick,omitempty"`
-	Likes      uint64    `json:"likes"`
-	CreatedAt  time.Time `json:"createdAt,omitempty"`
+	ID          uint64       `json:"id,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Content     string       `json:"content,omitempty"`
+	ContentHTML string       `json:"contentHtml,omitempty"`
+	AuthorID    uint64       `json:"authorId,omitempty"`
+	AuthorNick  string       `json:"authorNick,omitempty"`
+	Reposts     uint64       `json:"reposts"`
+	Quotes      uint64       `json:"quotes"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Mentions    []Mention    `json:"mentions,omitempty"`
+	// Reactions contém a quantidade de reações de cada tipo e MyReaction a reação do usuário que está
+	// consultando, quando houver. Likes é a quantidade de reações like, mantida para os clientes que
+	// ainda leem as curtidas
+	Reactions  map[string]uint64 `json:"reactions"`
+	MyReaction string            `json:"myReaction,omitempty"`
+	Likes      uint64            `json:"likes"`
+	Poll       *Poll             `json:"poll,omitempty"`
+	// ThreadID é a primeira parte da thread quando esta publicação é uma das partes seguintes. As
+	// partes de uma thread têm a posição, a quantidade total de partes e quantas vêm depois desta.
+	// Na criação, Thread contém as partes que seguem a publicação e, na resposta, as partes criadas
+	ThreadID        uint64        `json:"threadId,omitempty"`
+	ThreadPosition  int           `json:"threadPosition,omitempty"`
+	ThreadLength    int           `json:"threadLength,omitempty"`
+	ThreadRemaining int           `json:"threadRemaining,omitempty"`
+	Thread          []Publication `json:"thread,omitempty"`
+	// RepostOfID é a publicação original quando esta publicação é um repost, que não tem conteúdo
+	// próprio e exibe a original em RepostOf
+	RepostOfID uint64       `json:"repostOfId,omitempty"`
+	RepostOf   *Publication `json:"repostOf,omitempty"`
+	// QuotedID é a publicação citada por esta, exibida em Quoted. Quando a citada é excluída ou não
+	// pode ser vista, Quoted fica vazio e QuoteUnavailable é verdadeiro
+	QuotedID         uint64       `json:"quotedId,omitempty"`
+	Quoted           *Publication `json:"quoted,omitempty"`
+	QuoteUnavailable bool         `json:"quoteUnavailable,omitempty"`
+	BookmarkedByMe   bool         `json:"bookmarkedByMe"`
+	Pinned           bool         `json:"pinned,omitempty"`
+	Status           string       `json:"status,omitempty"`
+	Visibility       string       `json:"visibility,omitempty"`
+	PublishAt        *time.Time   `json:"publishAt,omitempty"`
+	EditedAt         *time.Time   `json:"editedAt,omitempty"`
+	// ExpiresAt é o momento em que a publicação deixa de ser exibida e é excluída
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt,omitempty"`
+}
+
+// Attachment representa uma imagem anexada a uma publicação
+type Attachment struct {
+	MediaID uint64 `json:"mediaId"`
+	AltText string `json:"altText"`
+	Media   Media  `json:"media"`
 }
 
 // Prepare irá chamar os métodos de validação e formatação da publicação
@@ -38,11 +122,182 @@ func (publication *Publication) Validate() error {
 		return errors.New("O campo de conteúdo deve ser preenchido")
 	}
 
+	if utf8.RuneCountInString(publication.Content) > maxContentSourceLength {
+		return fmt.Errorf("O conteúdo deve ter no máximo %d caracteres, contando a formatação", maxContentSourceLength)
+	}
+
+	if utf8.RuneCountInString(markdown.PlainText(strings.TrimSpace(publication.Content))) > maxContentLength {
+		return fmt.Errorf("O conteúdo deve ter no máximo %d caracteres visíveis", maxContentLength)
+	}
+
+	switch publication.Status {
+	case "", StatusPublished, StatusDraft:
+	case StatusScheduled:
+		if publication.PublishAt == nil || !publication.PublishAt.After(time.Now()) {
+			return errors.New("Publicações agendadas devem informar uma data futura em publishAt")
+		}
+	default:
+		return fmt.Errorf("O campo status deve ser %s, %s ou %s", StatusDraft, StatusScheduled, StatusPublished)
+	}
+
+	switch publication.Visibility {
+	case "", VisibilityPublic, VisibilityFollowers, VisibilityMentioned, VisibilityPrivate:
+	default:
+		return fmt.Errorf(
+			"O campo visibility deve ser %s, %s, %s ou %s",
+			VisibilityPublic, VisibilityFollowers, VisibilityMentioned, VisibilityPrivate,
+		)
+	}
+
+	if len(ParseTags(publication.Content)) > MaxTags {
+		return fmt.Errorf("É permitido usar no máximo %d hashtags", MaxTags)
+	}
+
+	if len(ParseMentions(publication.Content)) > MaxMentions {
+		return fmt.Errorf("É permitido mencionar no máximo %d usuários", MaxMentions)
+	}
+
+	if len(publication.Attachments) > MaxAttachments {
+		return fmt.Errorf("É permitido anexar no máximo %d imagens", MaxAttachments)
+	}
+
+	attached := make(map[uint64]bool, len(publication.Attachments))
+	for _, attachment := range publication.Attachments {
+		if attachment.MediaID == 0 {
+			return errors.New("Os anexos devem informar o id da mídia")
+		}
+
+		if attached[attachment.MediaID] {
+			return errors.New("A mesma mídia não pode ser anexada mais de uma vez")
+		}
+		attached[attachment.MediaID] = true
+
+		if strings.TrimSpace(attachment.AltText) == "" {
+			return errors.New("Os anexos devem ter um texto alternativo para acessibilidade")
+		}
+
+		if utf8.RuneCountInString(attachment.AltText) > maxAltTextLength {
+			return fmt.Errorf("O texto alternativo deve ter no máximo %d caracteres", maxAltTextLength)
+		}
+	}
+
+	var publishAt *time.Time
+	if publication.Status == StatusScheduled {
+		publishAt = publication.PublishAt
+	}
+
+	if publication.ExpiresAt != nil {
+		start := time.Now()
+		if publishAt != nil {
+			start = *publishAt
+		}
+
+		if !publication.ExpiresAt.After(start) {
+			return errors.New("A publicação deve expirar depois de publicada")
+		}
+	}
+
+	if publication.Poll != nil {
+		if err := publication.Poll.Validate(publishAt); err != nil {
+			return err
+		}
+	}
+
+	return publication.validateThread()
+}
+
+// validateThread verifica as partes que seguem a publicação. Threads são publicadas imediatamente,
+// pois as partes são distribuídas juntas
+func (publication *Publication) validateThread() error {
+	if len(publication.Thread) == 0 {
+		return nil
+	}
+
+	if publication.Status != "" && publication.Status != StatusPublished {
+		return errors.New("Threads não podem ser rascunhos nem agendadas")
+	}
+
+	if len(publication.Thread)+1 > MaxThreadLength {
+		return fmt.Errorf("Uma thread deve ter no máximo %d partes", MaxThreadLength)
+	}
+
+	for i := range publication.Thread {
+		part := &publication.Thread[i]
+		if len(part.Thread) > 0 {
+			return errors.New("As partes de uma thread não podem ter outras partes")
+		}
+
+		if err := part.Validate(); err != nil {
+			return fmt.Errorf("Parte %d da thread: %w", i+2, err)
+		}
+	}
+
 	return nil
 }
 
-// Format retira os espaços das extremidades dos campos
+// Format retira os espaços das extremidades dos campos e extrai as hashtags e as menções do conteúdo.
+// As menções precisam ter o id do usuário resolvido antes de serem gravadas. Sem uma situação
+// informada, a publicação é publicada imediatamente e, sem uma visibilidade, é pública
 func (publication *Publication) Format() {
 	publication.Title = strings.TrimSpace(publication.Title)
 	publication.Content = strings.TrimSpace(publication.Content)
+	publication.ContentHTML = markdown.ToHTML(publication.Content)
+	publication.Tags = ParseTags(publication.Content)
+	publication.Mentions = ParseMentions(publication.Content)
+
+	if publication.Status == "" {
+		publication.Status = StatusPublished
+	}
+
+	if publication.Status != StatusScheduled {
+		publication.PublishAt = nil
+	}
+
+	if publication.Visibility == "" {
+		publication.Visibility = VisibilityPublic
+	}
+
+	for i := range publication.Attachments {
+		publication.Attachments[i].AltText = strings.TrimSpace(publication.Attachments[i].AltText)
+	}
+
+	if publication.Poll != nil {
+		publication.Poll.Format()
+	}
+
+	for i := range publication.Thread {
+		publication.Thread[i].Format()
+	}
+}
+
+// VisibleTo informa se a visibilidade da publicação permite que o usuário a veja, considerando que
+// ele segue ou não o autor. As menções precisam estar carregadas. Bloqueios e contas privadas são
+// verificados separadamente
+func (publication Publication) VisibleTo(viewerID uint64, following bool) bool {
+	if viewerID == publication.AuthorID {
+		return true
+	}
+
+	switch publication.Visibility {
+	case VisibilityPublic:
+		return true
+	case VisibilityFollowers:
+		return following
+	case VisibilityMentioned:
+		for _, mention := range publication.Mentions {
+			if mention.UserID == viewerID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SearchResult é uma publicação encontrada pela busca, com a sua relevância e um trecho do conteúdo
+// com as palavras encontradas destacadas
+type SearchResult struct {
+	Publication
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
 }
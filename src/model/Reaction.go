@@ -0,0 +1,35 @@
+package model
+
+import (
+	"errors"
+	"strings"
+)
+
+// Tipos de reação possíveis a uma publicação
+const (
+	ReactionLike       = "like"
+	ReactionLove       = "love"
+	ReactionLaugh      = "laugh"
+	ReactionInsightful = "insightful"
+	ReactionSad        = "sad"
+)
+
+// Reaction representa a reação escolhida pelo usuário para uma publicação. Cada usuário tem no máximo
+// uma reação por publicação
+type Reaction struct {
+	Type string `json:"type"`
+}
+
+// Prepare formata e valida o tipo da reação
+func (reaction *Reaction) Prepare() error {
+	reaction.Type = strings.ToLower(strings.TrimSpace(reaction.Type))
+
+	switch reaction.Type {
+	case ReactionLike, ReactionLove, ReactionLaugh, ReactionInsightful, ReactionSad:
+		return nil
+	case "":
+		return errors.New("O campo type deve ser preenchido")
+	default:
+		return errors.New("O campo type deve ser like, love, laugh, insightful ou sad")
+	}
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Revision representa uma versão anterior de uma publicação, guardada a cada edição. CreatedAt é o
+// momento da edição que substituiu esta versão
+type Revision struct {
+	ID            uint64    `json:"id,omitempty"`
+	PublicationID uint64    `json:"publicationId,omitempty"`
+	Title         string    `json:"title"`
+	Content       string    `json:"content"`
+	CreatedAt     time.Time `json:"createdAt,omitempty"`
+}
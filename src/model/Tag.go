@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"api.devbook/src/markdown"
+	"api.devbook/src/text"
+)
+
+// Limites das hashtags de uma publicação
+const (
+	MaxTags      = 10
+	maxTagLength = 50
+)
+
+// Tag representa uma hashtag usada nas publicações
+type Tag struct {
+	ID        uint64    `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+// TrendingTag é uma hashtag em alta, com a pontuação que define a sua posição
+type TrendingTag struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// ParseTags retorna as hashtags do conteúdo em Markdown, sem o "#", normalizadas e sem repetições, na
+// ordem em que aparecem. Uma hashtag é formada por letras, dígitos e "_", deve conter ao menos uma
+// letra e não pode estar colada a uma palavra anterior. Hashtags em códigos, blocos de código e links
+// são ignoradas
+func ParseTags(content string) []string {
+	var (
+		tags []string
+		seen = make(map[string]bool)
+	)
+
+	visible := markdown.ParseText(content)
+
+	runes := []rune(visible.Plain)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' || (i > 0 && isTagRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		hasLetter := false
+		for end < len(runes) && isTagRune(runes[end]) {
+			hasLetter = hasLetter || unicode.IsLetter(runes[end])
+			end++
+		}
+
+		tag := NormalizeTag(string(runes[i+1 : end]))
+		if hasLetter && visible.IsProse(i, end) && utf8.RuneCountInString(tag) <= maxTagLength && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+
+		i = end - 1
+	}
+
+	return tags
+}
+
+// NormalizeTag converte a hashtag para a forma em que é armazenada: sem o "#", em minúsculas e sem
+// acentos, para que #Programação e #programacao sejam a mesma hashtag
+func NormalizeTag(tag string) string {
+	if len(tag) > 0 && tag[0] == '#' {
+		tag = tag[1:]
+	}
+
+	return text.Fold(tag)
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
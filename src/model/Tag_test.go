@@ -0,0 +1,50 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "texto comum normalizado e sem repetições",
+			content: "#Go e #programação, de novo #go",
+			want:    []string{"go", "programacao"},
+		},
+		{
+			name:    "sem letras ou colada a uma palavra",
+			content: "#123 e abc#def",
+		},
+		{
+			name:    "bloco de código",
+			content: "```\n@admin #secret\n```",
+		},
+		{
+			name:    "código",
+			content: "use `#include` aqui",
+		},
+		{
+			name:    "link",
+			content: "[#docs](https://example.com/#secao) e #fora",
+			want:    []string{"fora"},
+		},
+		{
+			name:    "dentro de negrito",
+			content: "**#go** e snake_case",
+			want:    []string{"go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTags(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTags(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,25 +2,119 @@ package model
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"api.devbook/src/security"
 	"github.com/badoux/checkmail"
 )
 
+// Visibilidades possíveis para a data de aniversário do usuário
+const (
+	BirthdayPublic    = "public"
+	BirthdayFollowers = "followers"
+	BirthdayPrivate   = "private"
+)
+
+// Limites dos campos do usuário
+const (
+	maxNameLength     = 50
+	maxNickLength     = 50
+	maxEmailLength    = 50
+	maxBioLength      = 160
+	maxLocationLength = 50
+	maxPronounsLength = 30
+	maxLinks          = 5
+	maxLinkLength     = 200
+	maxMediaRefLength = 255
+)
+
 // Representa um usuários utilizando a rede social
 type User struct {
 	// "omitempty" serve para ocultar parâmetros não recebidos para json
-	ID        uint64    `json:"id,omitempty"`
-	Name      string    `json:"name,omitempty"`
-	Nick      string    `json:"nick,omitempty"`
-	Email     string    `json:"email,omitempty"`
-	Password  string    `json:"password,omitempty"`
-	CreatedAt time.Time `json:"createdAt,omitempty"`
+	ID                 uint64      `json:"id,omitempty"`
+	Name               string      `json:"name,omitempty"`
+	Nick               string      `json:"nick,omitempty"`
+	Email              string      `json:"email,omitempty"`
+	Password           string      `json:"password,omitempty"`
+	Private            bool        `json:"private"`
+	Admin              bool        `json:"-"`
+	Bio                string      `json:"bio,omitempty"`
+	Location           string      `json:"location,omitempty"`
+	Links              []string    `json:"links,omitempty"`
+	Pronouns           string      `json:"pronouns,omitempty"`
+	Birthday           string      `json:"birthday,omitempty"`
+	BirthdayVisibility string      `json:"birthdayVisibility,omitempty"`
+	Avatar             string      `json:"avatar,omitempty"`
+	Header             string      `json:"header,omitempty"`
+	Counts             *UserCounts `json:"counts,omitempty"`
+	CreatedAt          time.Time   `json:"createdAt,omitempty"`
+}
+
+// UserCounts contém os totais de seguidores, de usuários seguidos e de publicações de um usuário
+type UserCounts struct {
+	Followers    uint64 `json:"followers"`
+	Following    uint64 `json:"following"`
+	Publications uint64 `json:"publications"`
+}
+
+// Public retorna a projeção pública do usuário, sem os dados que apenas o dono da conta pode ver.
+// A data de aniversário só é mantida quando a sua visibilidade é pública
+func (u User) Public() User {
+	u.Email = ""
+	u.Password = ""
+
+	if u.BirthdayVisibility != BirthdayPublic {
+		u.Birthday = ""
+	}
+	u.BirthdayVisibility = ""
+
+	return u
+}
+
+// Owner retorna a projeção completa do usuário, visível apenas para o dono da conta e administradores
+func (u User) Owner() User {
+	u.Password = ""
+
+	return u
+}
+
+// ProjectFor retorna a projeção do usuário adequada para quem está visualizando
+func (u User) ProjectFor(viewerID uint64, admin bool) User {
+	if admin || u.ID == viewerID {
+		return u.Owner()
+	}
+
+	return u.Public()
+}
+
+// ProjectUsers aplica a projeção adequada para quem está visualizando em cada usuário da lista
+func ProjectUsers(users []User, viewerID uint64, admin bool) []User {
+	for i := range users {
+		users[i] = users[i].ProjectFor(viewerID, admin)
+	}
+
+	return users
+}
+
+// UserSuggestion é a projeção resumida do usuário usada nas sugestões enquanto se digita
+type UserSuggestion struct {
+	ID     uint64 `json:"id"`
+	Nick   string `json:"nick"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+// Suggestion retorna a projeção resumida do usuário usada nas sugestões
+func (u User) Suggestion() UserSuggestion {
+	return UserSuggestion{u.ID, u.Nick, u.Name, u.Avatar}
 }
 
-// Prepare chama os métodos para validar e formatar os campos
+// Prepare chama os métodos para validar e formatar os campos. No estágio "profile" apenas os campos
+// do perfil são validados
 func (u *User) Prepare(stage string) error {
 	if err := u.verifyFields(stage); err != nil {
 		return err
@@ -34,6 +128,14 @@ func (u *User) Prepare(stage string) error {
 }
 
 func (u *User) verifyFields(stage string) error {
+	if err := u.verifyProfile(); err != nil {
+		return err
+	}
+
+	if stage == "profile" {
+		return nil
+	}
+
 	if u.Name == "" {
 		return errors.New("O campo nome deve ser preenchido")
 	}
@@ -50,6 +152,18 @@ func (u *User) verifyFields(stage string) error {
 		return errors.New("O e-mail inserido é inválido")
 	}
 
+	if err := verifyLength("nome", u.Name, maxNameLength); err != nil {
+		return err
+	}
+
+	if err := verifyLength("apelido", u.Nick, maxNickLength); err != nil {
+		return err
+	}
+
+	if err := verifyLength("email", u.Email, maxEmailLength); err != nil {
+		return err
+	}
+
 	if stage == "register" && u.Password == "" {
 		return errors.New("O campo senha deve ser preenchido")
 	}
@@ -57,10 +171,95 @@ func (u *User) verifyFields(stage string) error {
 	return nil
 }
 
+func (u *User) verifyProfile() error {
+	fields := []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"bio", u.Bio, maxBioLength},
+		{"localização", u.Location, maxLocationLength},
+		{"pronomes", u.Pronouns, maxPronounsLength},
+		{"avatar", u.Avatar, maxMediaRefLength},
+		{"capa", u.Header, maxMediaRefLength},
+	}
+
+	for _, field := range fields {
+		if err := verifyLength(field.name, field.value, field.max); err != nil {
+			return err
+		}
+	}
+
+	if len(u.Links) > maxLinks {
+		return fmt.Errorf("É permitido informar no máximo %d links", maxLinks)
+	}
+
+	for _, link := range u.Links {
+		if err := verifyLink(link); err != nil {
+			return err
+		}
+	}
+
+	if u.Birthday != "" {
+		birthday, err := time.Parse("2006-01-02", u.Birthday)
+		if err != nil {
+			return errors.New("A data de aniversário deve estar no formato AAAA-MM-DD")
+		}
+
+		if birthday.After(time.Now()) {
+			return errors.New("A data de aniversário não pode estar no futuro")
+		}
+	}
+
+	switch u.BirthdayVisibility {
+	case "", BirthdayPublic, BirthdayFollowers, BirthdayPrivate:
+	default:
+		return errors.New("A visibilidade do aniversário deve ser public, followers ou private")
+	}
+
+	return nil
+}
+
+func verifyLength(field, value string, max int) error {
+	if utf8.RuneCountInString(strings.TrimSpace(value)) > max {
+		return fmt.Errorf("O campo %s deve ter no máximo %d caracteres", field, max)
+	}
+
+	return nil
+}
+
+func verifyLink(link string) error {
+	link = strings.TrimSpace(link)
+
+	if utf8.RuneCountInString(link) > maxLinkLength {
+		return fmt.Errorf("Os links devem ter no máximo %d caracteres", maxLinkLength)
+	}
+
+	parsed, err := url.ParseRequestURI(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("O link %q é inválido", link)
+	}
+
+	return nil
+}
+
 func (u *User) formatFields(stage string) error {
 	u.Name = strings.TrimSpace(u.Name)
 	u.Nick = strings.TrimSpace(u.Nick)
 	u.Email = strings.TrimSpace(u.Email)
+	u.Bio = strings.TrimSpace(u.Bio)
+	u.Location = strings.TrimSpace(u.Location)
+	u.Pronouns = strings.TrimSpace(u.Pronouns)
+	u.Avatar = strings.TrimSpace(u.Avatar)
+	u.Header = strings.TrimSpace(u.Header)
+
+	for i, link := range u.Links {
+		u.Links[i] = strings.TrimSpace(link)
+	}
+
+	if u.BirthdayVisibility == "" {
+		u.BirthdayVisibility = BirthdayPrivate
+	}
 
 	if stage == "register" {
 		passwordHashed, err := security.Hash(u.Password)
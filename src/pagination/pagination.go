@@ -0,0 +1,176 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLimit é a quantidade de itens por página quando o parâmetro limit não é informado
+	DefaultLimit = 20
+
+	// MaxLimit é a maior quantidade de itens permitida em uma página
+	MaxLimit = 100
+)
+
+var (
+	errInvalidCursor = errors.New("O cursor informado é inválido")
+	errCursorKind    = errors.New("O cursor informado pertence a uma listagem com outra ordenação")
+)
+
+// Kind é a ordenação de uma listagem, que define o tipo de cursor aceito por ela
+type Kind int
+
+const (
+	// Chronological é a listagem em ordem cronológica, cujo cursor é a chave do último item da página
+	Chronological Kind = iota
+	// Ranked é a listagem ordenada por relevância, cujo cursor é a posição do próximo item
+	Ranked
+)
+
+// Cursor identifica a posição de um item em uma listagem ordenada da data de criação mais recente
+// para a mais antiga, usando o id para desempatar itens criados no mesmo instante
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint64
+}
+
+// Page contém os parâmetros da página solicitada. Listagens com ordem cronológica usam After, enquanto
+// listagens ordenadas por relevância, que não possuem uma chave estável, usam Offset
+type Page struct {
+	Kind   Kind
+	Limit  int
+	After  *Cursor
+	Offset int
+}
+
+// FromRequest lê os parâmetros limit e cursor da query da requisição de uma listagem em ordem
+// cronológica. Um cursor de uma listagem ordenada por relevância é recusado
+func FromRequest(r *http.Request) (Page, error) {
+	return fromRequest(r, Chronological)
+}
+
+// RankedFromRequest lê os parâmetros limit e cursor da query da requisição de uma listagem ordenada
+// por relevância. Um cursor de uma listagem em ordem cronológica é recusado
+func RankedFromRequest(r *http.Request) (Page, error) {
+	return fromRequest(r, Ranked)
+}
+
+func fromRequest(r *http.Request, kind Kind) (Page, error) {
+	page := Page{Kind: kind, Limit: DefaultLimit}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			return Page{}, errors.New("O parâmetro limit deve ser um número positivo")
+		}
+
+		if value > MaxLimit {
+			value = MaxLimit
+		}
+
+		page.Limit = value
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	if cursor == "" {
+		return page, nil
+	}
+
+	// Os cursores de uma ordenação não indicam uma posição na outra, e ignorá-los recomeçaria a listagem
+	if offset, ok := decodeOffset(cursor); ok {
+		if kind != Ranked {
+			return Page{}, errCursorKind
+		}
+
+		page.Offset = offset
+		return page, nil
+	}
+
+	after, err := Decode(cursor)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if kind != Chronological {
+		return Page{}, errCursorKind
+	}
+
+	page.After = &after
+	return page, nil
+}
+
+// Encode retorna a representação opaca do cursor, segura para ser usada em URLs
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.CreatedAt.UnixNano(), c.ID)))
+}
+
+// Decode interpreta um cursor gerado por Encode
+func Decode(encoded string) (Cursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, errInvalidCursor
+	}
+
+	var nanos int64
+	var id uint64
+	if _, err = fmt.Sscanf(string(decoded), "%d:%d", &nanos, &id); err != nil {
+		return Cursor{}, errInvalidCursor
+	}
+
+	return Cursor{CreatedAt: time.Unix(0, nanos), ID: id}, nil
+}
+
+// EncodeOffset retorna o cursor opaco que aponta para a posição informada de uma listagem ordenada
+// por relevância
+func EncodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+func decodeOffset(encoded string) (int, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || !strings.HasPrefix(string(decoded), "o:") {
+		return 0, false
+	}
+
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), "o:"))
+	if err != nil || offset < 0 {
+		return 0, false
+	}
+
+	return offset, true
+}
+
+// Slice retorna os itens da página a partir da posição Offset de uma listagem completa, junto com o
+// cursor da próxima página, que fica vazio quando não há mais itens
+func Slice[T any](items []T, page Page) ([]T, string) {
+	if page.Offset >= len(items) {
+		return []T{}, ""
+	}
+
+	end := page.Offset + page.Limit
+	if end >= len(items) {
+		return items[page.Offset:], ""
+	}
+
+	return items[page.Offset:end], EncodeOffset(end)
+}
+
+// Trim recebe até limit+1 itens, com as respectivas chaves, e retorna apenas os itens da página junto
+// com o cursor da próxima página, que fica vazio quando não há mais itens
+func Trim[T any](items []T, keys []Cursor, limit int) ([]T, string) {
+	if items == nil {
+		items = []T{}
+	}
+
+	if len(items) <= limit {
+		return items, ""
+	}
+
+	return items[:limit], keys[limit-1].Encode()
+}
@@ -0,0 +1,57 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestFromRequestCursorKind(t *testing.T) {
+	keyed := Cursor{CreatedAt: time.Unix(1700000000, 0), ID: 42}.Encode()
+	offset := EncodeOffset(40)
+
+	tests := []struct {
+		name    string
+		kind    Kind
+		cursor  string
+		want    Page
+		wantErr bool
+	}{
+		{name: "cronológica sem cursor", kind: Chronological, want: Page{Kind: Chronological, Limit: DefaultLimit}},
+		{name: "relevância sem cursor", kind: Ranked, want: Page{Kind: Ranked, Limit: DefaultLimit}},
+		{name: "cronológica com chave", kind: Chronological, cursor: keyed, want: Page{Kind: Chronological, Limit: DefaultLimit, After: &Cursor{ID: 42}}},
+		{name: "relevância com posição", kind: Ranked, cursor: offset, want: Page{Kind: Ranked, Limit: DefaultLimit, Offset: 40}},
+		{name: "cronológica com posição", kind: Chronological, cursor: offset, wantErr: true},
+		{name: "relevância com chave", kind: Ranked, cursor: keyed, wantErr: true},
+		{name: "cursor inválido", kind: Chronological, cursor: "???", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?cursor="+url.QueryEscape(tt.cursor), nil)
+
+			readPage := FromRequest
+			if tt.kind == Ranked {
+				readPage = RankedFromRequest
+			}
+
+			page, err := readPage(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if page.Kind != tt.want.Kind || page.Limit != tt.want.Limit || page.Offset != tt.want.Offset {
+				t.Errorf("page = %+v, want %+v", page, tt.want)
+			}
+
+			if (page.After == nil) != (tt.want.After == nil) || (page.After != nil && page.After.ID != tt.want.After.ID) {
+				t.Errorf("After = %v, want %v", page.After, tt.want.After)
+			}
+		})
+	}
+}
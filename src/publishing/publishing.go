@@ -0,0 +1,132 @@
+package publishing
+
+import (
+	"database/sql"
+	"log"
+
+	"api.devbook/src/media"
+	"api.devbook/src/model"
+	"api.devbook/src/repository"
+	"api.devbook/src/search"
+	"api.devbook/src/timeline"
+)
+
+// OnPublish executa os efeitos de uma publicação que acabou de ser publicada, seja ao ser criada, ao
+// publicar um rascunho ou pela publicação das agendadas: distribui a publicação nas linhas do tempo,
+// adiciona ao índice de busca e notifica os usuários mencionados e o autor da publicação citada. As
+// partes seguintes de uma thread não são distribuídas, pois a thread aparece pela primeira parte. Os
+// erros são registrados no log, pois a publicação já foi gravada
+func OnPublish(db *sql.DB, publication model.Publication) {
+	distribute(db, publication)
+
+	if err := search.Default.Add(search.DocumentOf(publication)); err != nil {
+		log.Printf("Erro ao atualizar o índice de busca: %v", err)
+	}
+
+	if err := notifyMentions(db, publication, nil); err != nil {
+		log.Printf("Erro ao notificar os usuários mencionados: %v", err)
+	}
+
+	if publication.Quoted != nil {
+		if err := repository.NewRepositoryOfNotifications(db).Create(model.Notification{
+			Type:          model.NotificationQuote,
+			ActorID:       publication.AuthorID,
+			PublicationID: publication.ID,
+		}, publication.Quoted.AuthorID); err != nil {
+			log.Printf("Erro ao notificar o autor da publicação citada: %v", err)
+		}
+	}
+}
+
+// OnEdit executa os efeitos da edição de uma publicação já publicada, notificando apenas os usuários
+// que não estavam entre as menções anteriores e retirando a publicação das linhas do tempo em que
+// estava apenas por causa das hashtags removidas
+func OnEdit(db *sql.DB, publication, previous model.Publication) {
+	// As novas hashtags podem levar a publicação a outras linhas do tempo
+	distribute(db, publication)
+
+	if publication.ThreadID == 0 {
+		if err := timeline.OnUntag(db, publication, removedTags(previous.Tags, publication.Tags)); err != nil {
+			log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+		}
+	}
+
+	if err := search.Default.Add(search.DocumentOf(publication)); err != nil {
+		log.Printf("Erro ao atualizar o índice de busca: %v", err)
+	}
+
+	if err := notifyMentions(db, publication, previous.Mentions); err != nil {
+		log.Printf("Erro ao notificar os usuários mencionados: %v", err)
+	}
+}
+
+// OnDelete executa os efeitos da exclusão de uma publicação, seja pelo autor ou pela expiração: retira
+// a publicação das linhas do tempo e do índice de busca e remove os arquivos das suas mídias. Os erros
+// são registrados no log, pois a publicação já foi excluída
+func OnDelete(db *sql.DB, publication model.Publication) {
+	if err := timeline.OnDelete(db, publication); err != nil {
+		log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+	}
+
+	if err := search.Default.Remove(publication.ID); err != nil {
+		log.Printf("Erro ao atualizar o índice de busca: %v", err)
+	}
+
+	for _, attachment := range publication.Attachments {
+		if err := media.Remove(attachment.Media.Key); err != nil {
+			log.Printf("Erro ao remover a mídia %d: %v", attachment.MediaID, err)
+		}
+	}
+}
+
+// distribute insere a publicação nas linhas do tempo, exceto quando ela é uma das partes seguintes de
+// uma thread
+func distribute(db *sql.DB, publication model.Publication) {
+	if publication.ThreadID != 0 {
+		return
+	}
+
+	if err := timeline.OnPublish(db, publication); err != nil {
+		log.Printf("Erro ao atualizar as linhas do tempo: %v", err)
+	}
+}
+
+// notifyMentions notifica os usuários mencionados na publicação que não estavam entre as menções
+// anteriores, evitando notificar novamente a cada edição
+func notifyMentions(db *sql.DB, publication model.Publication, previous []model.Mention) error {
+	notified := make(map[uint64]bool, len(previous))
+	for _, mention := range previous {
+		notified[mention.UserID] = true
+	}
+
+	var userIDs []uint64
+	for _, mention := range publication.Mentions {
+		if mention.UserID != 0 && !notified[mention.UserID] {
+			notified[mention.UserID] = true
+			userIDs = append(userIDs, mention.UserID)
+		}
+	}
+
+	return repository.NewRepositoryOfNotifications(db).Create(model.Notification{
+		Type:          model.NotificationMention,
+		ActorID:       publication.AuthorID,
+		PublicationID: publication.ID,
+	}, userIDs...)
+}
+
+// removedTags retorna as hashtags anteriores que não estão entre as atuais
+func removedTags(previous, current []string) []string {
+	kept := make(map[string]bool, len(current))
+	for _, tag := range current {
+		kept[tag] = true
+	}
+
+	var removed []string
+	for _, tag := range previous {
+		if !kept[tag] {
+			removed = append(removed, tag)
+		}
+	}
+
+	return removed
+}
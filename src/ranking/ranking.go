@@ -0,0 +1,92 @@
+package ranking
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Weights define a importância de cada sinal no cálculo da relevância de uma publicação
+type Weights struct {
+	// HalfLife é o tempo após o qual a relevância de uma publicação cai pela metade
+	HalfLife time.Duration
+	// Reactions multiplica o logaritmo da quantidade de reações à publicação
+	Reactions float64
+	// Quotes multiplica o logaritmo da quantidade de citações da publicação. Como a API não tem
+	// comentários, as citações, que respondem à publicação com um texto próprio, medem a conversa
+	// em torno dela
+	Quotes float64
+	// Affinity multiplica o logaritmo da quantidade de interações do leitor com o autor
+	Affinity float64
+	// SecondDegree multiplica a relevância das publicações de autores que o leitor não segue, mas
+	// que são seguidos por quem ele segue
+	SecondDegree float64
+}
+
+// DefaultWeights são os pesos usados quando nenhum outro é configurado
+var DefaultWeights = Weights{
+	HalfLife:     6 * time.Hour,
+	Reactions:    1,
+	Quotes:       1.5,
+	Affinity:     2,
+	SecondDegree: 0.5,
+}
+
+// Candidate contém os sinais de uma publicação que pode ser exibida no feed
+type Candidate struct {
+	PublicationID uint64
+	CreatedAt     time.Time
+	Reactions     uint64
+	Quotes        uint64
+	Affinity      uint64
+	SecondDegree  bool
+}
+
+// Score calcula a relevância da publicação no instante informado. O engajamento, medido pelas
+// reações, pelas citações e pela afinidade com o autor, é reduzido pela metade a cada HalfLife desde a
+// publicação
+func Score(candidate Candidate, weights Weights, now time.Time) float64 {
+	engagement := 1 +
+		weights.Reactions*math.Log1p(float64(candidate.Reactions)) +
+		weights.Quotes*math.Log1p(float64(candidate.Quotes)) +
+		weights.Affinity*math.Log1p(float64(candidate.Affinity))
+
+	age := now.Sub(candidate.CreatedAt)
+	if age < 0 {
+		age = 0
+	}
+
+	score := engagement
+	if weights.HalfLife > 0 {
+		score *= math.Exp2(-float64(age) / float64(weights.HalfLife))
+	}
+
+	if candidate.SecondDegree {
+		score *= weights.SecondDegree
+	}
+
+	return score
+}
+
+// Rank ordena os candidatos da maior para a menor relevância, desempatando pela publicação mais recente
+func Rank(candidates []Candidate, weights Weights, now time.Time) []Candidate {
+	scores := make(map[uint64]float64, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate.PublicationID] = Score(candidate, weights, now)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if scores[a.PublicationID] != scores[b.PublicationID] {
+			return scores[a.PublicationID] > scores[b.PublicationID]
+		}
+
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+
+		return a.PublicationID > b.PublicationID
+	})
+
+	return candidates
+}
@@ -0,0 +1,161 @@
+package ranking
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestScore(t *testing.T) {
+	weights := Weights{HalfLife: 6 * time.Hour, Reactions: 1, Quotes: 1.5, Affinity: 2, SecondDegree: 0.5}
+
+	tests := []struct {
+		name      string
+		weights   Weights
+		candidate Candidate
+		want      float64
+	}{
+		{
+			name:      "sem engajamento no instante da publicação",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now},
+			want:      1,
+		},
+		{
+			name:      "reações",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now, Reactions: 9},
+			want:      1 + math.Log1p(9),
+		},
+		{
+			name:      "citações",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now, Quotes: 4},
+			want:      1 + 1.5*math.Log1p(4),
+		},
+		{
+			name:      "afinidade com o autor",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now, Affinity: 3},
+			want:      1 + 2*math.Log1p(3),
+		},
+		{
+			name:      "todos os sinais",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now, Reactions: 1, Quotes: 2, Affinity: 3},
+			want:      1 + math.Log1p(1) + 1.5*math.Log1p(2) + 2*math.Log1p(3),
+		},
+		{
+			name:      "metade após uma meia-vida",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now.Add(-6 * time.Hour), Reactions: 9},
+			want:      (1 + math.Log1p(9)) / 2,
+		},
+		{
+			name:      "um quarto após duas meias-vidas",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now.Add(-12 * time.Hour)},
+			want:      0.25,
+		},
+		{
+			name:      "segundo grau",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now, Reactions: 9, SecondDegree: true},
+			want:      (1 + math.Log1p(9)) * 0.5,
+		},
+		{
+			name:      "data futura tratada como agora",
+			weights:   weights,
+			candidate: Candidate{CreatedAt: now.Add(time.Hour)},
+			want:      1,
+		},
+		{
+			name:      "sem meia-vida não há decaimento",
+			weights:   Weights{Reactions: 1},
+			candidate: Candidate{CreatedAt: now.Add(-30 * 24 * time.Hour), Reactions: 9},
+			want:      1 + math.Log1p(9),
+		},
+		{
+			name:      "pesos zerados ignoram os sinais",
+			weights:   Weights{HalfLife: time.Hour, SecondDegree: 1},
+			candidate: Candidate{CreatedAt: now, Reactions: 100, Quotes: 100, Affinity: 100, SecondDegree: true},
+			want:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Score(tt.candidate, tt.weights, now); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name       string
+		weights    Weights
+		candidates []Candidate
+		want       []uint64
+	}{
+		{
+			name:    "mais engajamento primeiro",
+			weights: DefaultWeights,
+			candidates: []Candidate{
+				{PublicationID: 1, CreatedAt: now, Reactions: 1},
+				{PublicationID: 2, CreatedAt: now, Reactions: 50},
+				{PublicationID: 3, CreatedAt: now, Quotes: 3},
+			},
+			want: []uint64{2, 3, 1},
+		},
+		{
+			name:    "recência supera o engajamento antigo",
+			weights: DefaultWeights,
+			candidates: []Candidate{
+				{PublicationID: 1, CreatedAt: now.Add(-72 * time.Hour), Reactions: 1000},
+				{PublicationID: 2, CreatedAt: now, Reactions: 1},
+			},
+			want: []uint64{2, 1},
+		},
+		{
+			name:    "segundo grau abaixo de quem é seguido",
+			weights: DefaultWeights,
+			candidates: []Candidate{
+				{PublicationID: 1, CreatedAt: now, Reactions: 5, SecondDegree: true},
+				{PublicationID: 2, CreatedAt: now, Reactions: 5},
+			},
+			want: []uint64{2, 1},
+		},
+		{
+			name:    "empate desfeito pela mais recente e depois pelo id",
+			weights: Weights{Reactions: 1},
+			candidates: []Candidate{
+				{PublicationID: 1, CreatedAt: now.Add(-time.Hour)},
+				{PublicationID: 2, CreatedAt: now},
+				{PublicationID: 3, CreatedAt: now},
+			},
+			want: []uint64{3, 2, 1},
+		},
+		{
+			name:    "vazio",
+			weights: DefaultWeights,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []uint64
+			for _, candidate := range Rank(tt.candidates, tt.weights, now) {
+				got = append(got, candidate.PublicationID)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rank() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
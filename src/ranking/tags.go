@@ -0,0 +1,54 @@
+package ranking
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// TagUsage é a quantidade de autores distintos que usaram uma hashtag em um determinado momento
+type TagUsage struct {
+	Tag     string
+	At      time.Time
+	Authors uint64
+}
+
+// TagScore é a pontuação de uma hashtag entre as hashtags em alta
+type TagScore struct {
+	Tag   string
+	Score float64
+}
+
+// Trending soma os usos de cada hashtag, com o peso de cada uso reduzido pela metade a cada halfLife
+// até o instante informado, e retorna as limit hashtags com maior pontuação. Contar autores em vez de
+// publicações impede que uma única conta coloque uma hashtag em alta
+func Trending(usages []TagUsage, halfLife time.Duration, now time.Time, limit int) []TagScore {
+	scores := make(map[string]float64)
+	for _, usage := range usages {
+		age := now.Sub(usage.At)
+		if age < 0 {
+			age = 0
+		}
+
+		scores[usage.Tag] += float64(usage.Authors) * math.Exp2(-float64(age)/float64(halfLife))
+	}
+
+	trending := make([]TagScore, 0, len(scores))
+	for tag, score := range scores {
+		trending = append(trending, TagScore{tag, score})
+	}
+
+	sort.Slice(trending, func(i, j int) bool {
+		if trending[i].Score != trending[j].Score {
+			return trending[i].Score > trending[j].Score
+		}
+
+		return trending[i].Tag < trending[j].Tag
+	})
+
+	if len(trending) > limit {
+		trending = trending[:limit]
+	}
+
+	return trending
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// notBlockedClause retorna a condição SQL que exclui os usuários que possuem bloqueio com o usuário
+// que está consultando. A coluna informada deve conter o id do usuário a ser verificado e a
+// condição espera o id do usuário que está consultando como argumento duas vezes
+func notBlockedClause(column string) string {
+	return fmt.Sprintf(
+		`NOT EXISTS (
+			SELECT 1 FROM blocks AS b
+			WHERE (b.userId = ? AND b.blockedId = %[1]s) OR (b.blockedId = ? AND b.userId = %[1]s)
+		)`,
+		column,
+	)
+}
+
+// notMutedClause retorna a condição SQL que exclui os usuários silenciados pelo usuário que está
+// consultando. A condição espera o id do usuário que está consultando como argumento
+func notMutedClause(column string) string {
+	return fmt.Sprintf(
+		"NOT EXISTS (SELECT 1 FROM mutes AS m WHERE m.userId = ? AND m.mutedId = %s)",
+		column,
+	)
+}
+
+// Block registra o bloqueio de um usuário e desfaz as relações de seguidor e as solicitações entre os
+// dois. Retorna se o usuário seguia o bloqueado e se o bloqueado seguia o usuário
+func (repo Users) Block(id, blockedID uint64) (bool, bool, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return false, false, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("INSERT ignore INTO blocks (userId, blockedId) VALUES (?, ?)", id, blockedID); err != nil {
+		return false, false, err
+	}
+
+	following, err := deleteFollower(tx, blockedID, id)
+	if err != nil {
+		return false, false, err
+	}
+
+	followedBy, err := deleteFollower(tx, id, blockedID)
+	if err != nil {
+		return false, false, err
+	}
+
+	if _, err = tx.Exec(
+		`DELETE FROM follow_requests
+		WHERE (userId = ? AND requesterId = ?) OR (userId = ? AND requesterId = ?)`,
+		id, blockedID, blockedID, id,
+	); err != nil {
+		return false, false, err
+	}
+
+	return following, followedBy, tx.Commit()
+}
+
+// deleteFollower desfaz na transação a relação de seguidor, informando se ela existia
+func deleteFollower(tx *sql.Tx, id, followerID uint64) (bool, error) {
+	result, err := tx.Exec("DELETE FROM followers WHERE userId = ? AND followerId = ?", id, followerID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Unblock remove o bloqueio de um usuário
+func (repo Users) Unblock(id, blockedID uint64) error {
+	statement, err := repo.db.Prepare("DELETE FROM blocks WHERE userId = ? AND blockedId = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(id, blockedID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IsBlocked verifica se existe um bloqueio entre os dois usuários, em qualquer direção
+func (repo Users) IsBlocked(id, otherID uint64) (bool, error) {
+	row, err := repo.db.Query(
+		`SELECT 1 FROM blocks
+		WHERE (userId = ? AND blockedId = ?) OR (userId = ? AND blockedId = ?) LIMIT 1`,
+		id, otherID, otherID, id,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer row.Close()
+
+	return row.Next(), row.Err()
+}
+
+// GetAllBlocked retorna uma página dos usuários bloqueados pelo usuário
+func (repo Users) GetAllBlocked(id uint64, page pagination.Page) ([]model.User, string, error) {
+	after, order, cursorArgs := pageClauses("b.createdAt", "u.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT `+userColumns+`, b.createdAt FROM blocks AS b
+		INNER JOIN users AS u ON b.blockedId = u.id WHERE b.userId = ?`+after+order,
+		append([]interface{}{id}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	return scanUserPage(rows, page)
+}
+
+// Mute silencia um usuário, ocultando as suas publicações do feed
+func (repo Users) Mute(id, mutedID uint64) error {
+	statement, err := repo.db.Prepare("INSERT ignore INTO mutes (userId, mutedId) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(id, mutedID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Unmute deixa de silenciar um usuário
+func (repo Users) Unmute(id, mutedID uint64) error {
+	statement, err := repo.db.Prepare("DELETE FROM mutes WHERE userId = ? AND mutedId = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(id, mutedID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAllMuted retorna uma página dos usuários silenciados pelo usuário
+func (repo Users) GetAllMuted(id uint64, page pagination.Page) ([]model.User, string, error) {
+	after, order, cursorArgs := pageClauses("m.createdAt", "u.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT `+userColumns+`, m.createdAt FROM mutes AS m
+		INNER JOIN users AS u ON m.mutedId = u.id WHERE m.userId = ?`+after+order,
+		append([]interface{}{id}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	return scanUserPage(rows, page)
+}
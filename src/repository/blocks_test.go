@@ -0,0 +1,54 @@
+package repository
+
+import "testing"
+
+func TestBlockReportsFollows(t *testing.T) {
+	db := openTestDB(t)
+
+	tests := []struct {
+		name       string
+		following  bool
+		followedBy bool
+	}{
+		{name: "sem relação"},
+		{name: "seguia o bloqueado", following: true},
+		{name: "seguido pelo bloqueado", followedBy: true},
+		{name: "seguiam um ao outro", following: true, followedBy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewRepositoryOfUsers(db)
+
+			userID := createTestUser(t, db)
+			blockedID := createTestUser(t, db)
+
+			if tt.following {
+				if err := repo.Follow(userID, blockedID); err != nil {
+					t.Fatalf("Follow: %v", err)
+				}
+			}
+
+			if tt.followedBy {
+				if err := repo.Follow(blockedID, userID); err != nil {
+					t.Fatalf("Follow: %v", err)
+				}
+			}
+
+			following, followedBy, err := repo.Block(userID, blockedID)
+			if err != nil {
+				t.Fatalf("Block: %v", err)
+			}
+
+			if following != tt.following || followedBy != tt.followedBy {
+				t.Errorf("Block = %v, %v, want %v, %v", following, followedBy, tt.following, tt.followedBy)
+			}
+
+			for _, pair := range [][2]uint64{{userID, blockedID}, {blockedID, userID}} {
+				if still, err := repo.IsFollowing(pair[0], pair[1]); err != nil || still {
+					t.Errorf("IsFollowing(%d, %d) = %v, %v depois do bloqueio", pair[0], pair[1], still, err)
+				}
+			}
+		})
+	}
+}
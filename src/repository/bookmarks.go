@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// Bookmarks representa um repositório de publicações salvas e de coleções
+type Bookmarks struct {
+	db *sql.DB
+}
+
+// NewRepositoryOfBookmarks cria um repositório de publicações salvas e de coleções
+func NewRepositoryOfBookmarks(db *sql.DB) *Bookmarks {
+	return &Bookmarks{db}
+}
+
+// Save salva a publicação para o usuário na coleção informada, ou fora de coleções quando o id da
+// coleção é zero. Salvar novamente uma publicação apenas a move de coleção
+func (repo Bookmarks) Save(userID, publicationID, collectionID uint64) error {
+	_, err := repo.db.Exec(
+		`INSERT INTO bookmarks (userId, publicationId, collectionId) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE collectionId = VALUES(collectionId)`,
+		userID, publicationID, nullableID(collectionID),
+	)
+	return err
+}
+
+// Remove retira a publicação das salvas pelo usuário
+func (repo Bookmarks) Remove(userID, publicationID uint64) error {
+	_, err := repo.db.Exec("DELETE FROM bookmarks WHERE userId = ? AND publicationId = ?", userID, publicationID)
+	return err
+}
+
+// GetAll retorna uma página das publicações salvas pelo usuário, das salvas mais recentemente para as
+// mais antigas, filtrando pela coleção quando o id dela é diferente de zero. Publicações que o usuário
+// não pode mais ver são omitidas
+func (repo Bookmarks) GetAll(userID, collectionID uint64, page pagination.Page) ([]model.Publication, string, error) {
+	after, order, cursorArgs := pageClauses("b.createdAt", "p.id", page)
+
+	args := []interface{}{userID, userID, userID, userID, userID, userID, userID, userID}
+
+	collection := ""
+	if collectionID != 0 {
+		collection = " AND b.collectionId = ?"
+		args = append(args, collectionID)
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT `+publicationColumns+`, b.createdAt FROM bookmarks AS b
+		INNER JOIN publications AS p ON b.publicationId = p.id
+		INNER JOIN users AS u ON p.authorId = u.id
+		WHERE b.userId = ? AND `+publishedClause+` AND `+notExpiredClause+`
+		AND `+notBlockedClause("p.authorId")+` AND `+canViewClause("p.authorId")+
+			` AND `+visibleClause("p")+collection+after+order,
+		append(args, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var (
+		publications []model.Publication
+		keys         []pagination.Cursor
+	)
+
+	for rows.Next() {
+		var key time.Time
+
+		publication, err := scanPublicationRow(rows, &key)
+		if err != nil {
+			return nil, "", err
+		}
+
+		publications = append(publications, publication)
+		keys = append(keys, pagination.Cursor{CreatedAt: key, ID: publication.ID})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	publications, next := pagination.Trim(publications, keys, page.Limit)
+
+	publications, err = NewRepositoryOfPublications(repo.db).WithViewer(userID).loadRelations(publications)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return publications, next, nil
+}
+
+// CreateCollection cria uma coleção para o usuário
+func (repo Bookmarks) CreateCollection(collection model.Collection) (uint64, error) {
+	result, err := repo.db.Exec(
+		"INSERT INTO bookmark_collections (userId, name) VALUES (?, ?)",
+		collection.UserID, collection.Name,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	collectionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(collectionID), nil
+}
+
+// GetCollection retorna a coleção do usuário, ou sql.ErrNoRows caso ela não exista ou pertença a outro
+// usuário
+func (repo Bookmarks) GetCollection(userID, collectionID uint64) (model.Collection, error) {
+	var collection model.Collection
+
+	err := repo.db.QueryRow(
+		"SELECT id, userId, name, createdAt FROM bookmark_collections WHERE id = ? AND userId = ?",
+		collectionID, userID,
+	).Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.CreatedAt)
+
+	return collection, err
+}
+
+// CollectionNameExists verifica se o usuário já possui outra coleção com o nome informado
+func (repo Bookmarks) CollectionNameExists(userID, exceptID uint64, name string) (bool, error) {
+	row, err := repo.db.Query(
+		"SELECT 1 FROM bookmark_collections WHERE userId = ? AND name = ? AND id <> ?",
+		userID, name, exceptID,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer row.Close()
+
+	return row.Next(), row.Err()
+}
+
+// GetAllCollections retorna todas as coleções do usuário em ordem alfabética
+func (repo Bookmarks) GetAllCollections(userID uint64) ([]model.Collection, error) {
+	rows, err := repo.db.Query(
+		"SELECT id, userId, name, createdAt FROM bookmark_collections WHERE userId = ? ORDER BY name",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []model.Collection{}
+	for rows.Next() {
+		var collection model.Collection
+		if err = rows.Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		collections = append(collections, collection)
+	}
+
+	return collections, rows.Err()
+}
+
+// RenameCollection altera o nome da coleção do usuário
+func (repo Bookmarks) RenameCollection(userID, collectionID uint64, name string) error {
+	_, err := repo.db.Exec(
+		"UPDATE bookmark_collections SET name = ? WHERE id = ? AND userId = ?",
+		name, collectionID, userID,
+	)
+	return err
+}
+
+// DeleteCollection exclui a coleção do usuário. As publicações salvas nela continuam salvas, fora de
+// coleções
+func (repo Bookmarks) DeleteCollection(userID, collectionID uint64) error {
+	_, err := repo.db.Exec("DELETE FROM bookmark_collections WHERE id = ? AND userId = ?", collectionID, userID)
+	return err
+}
+
+// loadBookmarks marca as publicações salvas pelo usuário que está consultando
+func (repo Publications) loadBookmarks(publications []model.Publication) error {
+	if repo.viewerID == 0 || len(publications) == 0 {
+		return nil
+	}
+
+	indexes := make(map[uint64][]int, len(publications))
+	args := make([]interface{}, 0, len(publications)+1)
+	args = append(args, repo.viewerID)
+	for i, publication := range publications {
+		indexes[publication.ID] = append(indexes[publication.ID], i)
+		args = append(args, publication.ID)
+	}
+
+	rows, err := repo.db.Query(
+		"SELECT publicationId FROM bookmarks WHERE userId = ? AND publicationId IN ("+placeholders(len(publications))+")",
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publicationID uint64
+		if err = rows.Scan(&publicationID); err != nil {
+			return err
+		}
+
+		for _, i := range indexes[publicationID] {
+			publications[i].BookmarkedByMe = true
+		}
+	}
+
+	return rows.Err()
+}
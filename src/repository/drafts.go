@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// GetDrafts retorna uma página dos rascunhos e das publicações agendadas do autor, que só podem ser
+// vistos por ele
+func (repo Publications) GetDrafts(authorID uint64, page pagination.Page) ([]model.Publication, string, error) {
+	after, order, cursorArgs := pageClauses("p.createdAt", "p.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT `+publicationColumns+` FROM publications AS p
+		INNER JOIN users AS u ON p.authorId = u.id
+		WHERE p.authorId = ? AND NOT `+publishedClause+` AND `+notExpiredClause+after+order,
+		append([]interface{}{authorID}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	return repo.WithViewer(authorID).scanPage(rows, page)
+}
+
+// PublishDue publica até limit publicações agendadas cuja data já chegou e retorna os seus ids. As
+// linhas são bloqueadas com SKIP LOCKED, de modo que várias instâncias da API possam executar a
+// tarefa ao mesmo tempo sem publicar a mesma publicação duas vezes. A data de criação passa a ser a
+// data agendada
+func (repo Publications) PublishDue(limit int) ([]uint64, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`SELECT id FROM publications WHERE status = ? AND publishAt <= current_timestamp()
+		ORDER BY publishAt LIMIT ? FOR UPDATE SKIP LOCKED`,
+		model.StatusScheduled, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	publicationIDs, err := scanIDs(rows)
+	rows.Close()
+	if err != nil || len(publicationIDs) == 0 {
+		return nil, err
+	}
+
+	args := make([]interface{}, 0, len(publicationIDs)+1)
+	args = append(args, model.StatusPublished)
+	for _, publicationID := range publicationIDs {
+		args = append(args, publicationID)
+	}
+
+	if _, err = tx.Exec(
+		`UPDATE publications SET status = ?, createdAt = publishAt, publishAt = NULL
+		WHERE id IN (`+placeholders(len(publicationIDs))+`)`,
+		args...,
+	); err != nil {
+		return nil, err
+	}
+
+	for _, publicationID := range publicationIDs {
+		if err = onPublished(tx, publicationID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return publicationIDs, nil
+}
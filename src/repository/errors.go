@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Códigos dos erros do MySQL tratados pelos repositórios
+const (
+	// errNoReferencedRow indica que a linha referenciada por uma chave estrangeira não existe
+	errNoReferencedRow = 1452
+	// errDuplicateEntry indica que a linha violaria uma chave única
+	errDuplicateEntry = 1062
+)
+
+// isMySQLError informa se o erro foi retornado pelo MySQL com o código informado
+func isMySQLError(err error, number uint16) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == number
+}
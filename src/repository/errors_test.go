@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIsMySQLError(t *testing.T) {
+	duplicate := &mysql.MySQLError{Number: errDuplicateEntry, Message: "Duplicate entry"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "mesmo código", err: duplicate, want: true},
+		{name: "erro embrulhado", err: fmt.Errorf("inserir: %w", duplicate), want: true},
+		{name: "outro código", err: &mysql.MySQLError{Number: errNoReferencedRow}},
+		{name: "outro erro", err: errors.New("falhou")},
+		{name: "sem erro"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMySQLError(tt.err, errDuplicateEntry); got != tt.want {
+				t.Errorf("isMySQLError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package repository
+
+import "api.devbook/src/model"
+
+// notExpiredClause restringe a consulta às publicações que ainda não expiraram. As publicadas deixam de
+// ser lidas assim que expiram, mesmo antes de serem excluídas. Rascunhos e publicações agendadas não
+// expiram, para que o autor ainda possa encontrá-los e excluí-los, mas não podem mais ser publicados
+const notExpiredClause = "(p.status <> '" + model.StatusPublished + "' OR p.expiresAt IS NULL OR p.expiresAt > current_timestamp())"
+
+// GetExpired retorna até limit publicações publicadas que expiraram, com os anexos e as hashtags
+// necessários para retirá-las das linhas do tempo e remover as suas mídias. As partes seguintes de
+// threads vêm antes das primeiras partes, que ao serem excluídas levariam as seguintes junto
+func (repo Publications) GetExpired(limit int) ([]model.Publication, error) {
+	rows, err := repo.db.Query(
+		`SELECT `+publicationColumns+` FROM publications AS p INNER JOIN users AS u ON p.authorId = u.id
+		WHERE p.expiresAt <= current_timestamp() AND `+publishedClause+`
+		ORDER BY p.threadId IS NULL, p.expiresAt, p.id LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publications, err := scanPublicationRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = repo.loadAttachments(publications); err != nil {
+		return nil, err
+	}
+
+	if err = repo.loadTags(publications); err != nil {
+		return nil, err
+	}
+
+	return publications, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"api.devbook/src/model"
+	"api.devbook/src/search"
+)
+
+// expire faz as publicações informadas expirarem um minuto atrás
+func expire(t *testing.T, db *sql.DB, publicationIDs ...uint64) {
+	t.Helper()
+
+	for _, publicationID := range publicationIDs {
+		if _, err := db.Exec(
+			"UPDATE publications SET expiresAt = current_timestamp() - INTERVAL 1 MINUTE WHERE id = ?",
+			publicationID,
+		); err != nil {
+			t.Fatalf("expirar publicação %d: %v", publicationID, err)
+		}
+	}
+}
+
+func containsID(publications []model.Publication, publicationID uint64) bool {
+	for _, publication := range publications {
+		if publication.ID == publicationID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestExpiredAreNotRead(t *testing.T) {
+	db := openTestDB(t)
+
+	authorID := createTestUser(t, db)
+	viewerID := createTestUser(t, db)
+
+	word := fmt.Sprintf("expira%d", time.Now().UnixNano())
+	publicationID := createTestPublication(t, db, authorID, model.Publication{
+		Content: "thread que expira " + word,
+		Thread:  []model.Publication{{Title: "Teste", Content: "segunda parte"}},
+	})
+
+	repo := NewRepositoryOfPublications(db)
+	searcher := search.NewMySQL(db, ViewableClause)
+	query := search.Query{Terms: []string{word}, ViewerID: viewerID}
+
+	thread, err := repo.GetThread(publicationID)
+	if err != nil || len(thread) != 2 {
+		t.Fatalf("GetThread antes de expirar = %d partes, %v", len(thread), err)
+	}
+
+	hits, err := searcher.Search(query, 10)
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("Search antes de expirar = %v, %v", hits, err)
+	}
+
+	expire(t, db, publicationID, thread[1].ID)
+
+	publication, err := repo.GetById(publicationID)
+	if err != nil || publication.ID != 0 {
+		t.Errorf("GetById = %d, %v, want nenhuma", publication.ID, err)
+	}
+
+	publications, err := repo.GetByIDs(viewerID, []uint64{publicationID, thread[1].ID})
+	if err != nil || len(publications) != 0 {
+		t.Errorf("GetByIDs = %d publicações, %v, want nenhuma", len(publications), err)
+	}
+
+	if thread, err = repo.GetThread(publicationID); err != nil || len(thread) != 0 {
+		t.Errorf("GetThread = %d partes, %v, want nenhuma", len(thread), err)
+	}
+
+	if hits, err = searcher.Search(query, 10); err != nil || len(hits) != 0 {
+		t.Errorf("Search = %v, %v, want nenhum resultado", hits, err)
+	}
+
+	indexed, err := repo.GetAllForSearch()
+	if err != nil || containsID(indexed, publicationID) {
+		t.Errorf("GetAllForSearch contém a publicação expirada: %v", err)
+	}
+}
+
+func TestGetExpired(t *testing.T) {
+	db := openTestDB(t)
+
+	authorID := createTestUser(t, db)
+	reposterID := createTestUser(t, db)
+
+	publishedID := createTestPublication(t, db, authorID, model.Publication{})
+	draftID := createTestPublication(t, db, authorID, model.Publication{Status: model.StatusDraft})
+	repostID := createTestPublication(t, db, reposterID, model.Publication{RepostOfID: publishedID})
+
+	expire(t, db, publishedID, draftID)
+
+	repo := NewRepositoryOfPublications(db)
+
+	expired, err := repo.GetExpired(1000)
+	if err != nil {
+		t.Fatalf("GetExpired: %v", err)
+	}
+
+	if !containsID(expired, publishedID) {
+		t.Errorf("GetExpired não contém a publicação publicada que expirou")
+	}
+
+	if containsID(expired, draftID) {
+		t.Errorf("GetExpired contém um rascunho")
+	}
+
+	// O rascunho continua disponível para o autor excluí-lo
+	if draft, err := repo.GetById(draftID); err != nil || draft.ID != draftID {
+		t.Errorf("GetById(rascunho) = %d, %v", draft.ID, err)
+	}
+
+	reposts, err := repo.GetReposts([]uint64{publishedID})
+	if err != nil || len(reposts) != 1 || reposts[0].ID != repostID || reposts[0].AuthorID != reposterID {
+		t.Errorf("GetReposts = %+v, %v", reposts, err)
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"api.devbook/src/ranking"
+)
+
+// GetRankingCandidates retorna os sinais das publicações criadas a partir de since que podem aparecer
+// no feed por relevância do usuário: as dos usuários que ele segue e as dos usuários com conta pública
+// seguidos por eles, exceto as de usuários bloqueados ou silenciados e as que a visibilidade não
+// permite que ele veja
+func (repo Publications) GetRankingCandidates(id uint64, since time.Time, limit int) ([]ranking.Candidate, error) {
+	rows, err := repo.db.Query(
+		`SELECT p.id, p.createdAt, p.reactions, p.quotes,
+		(SELECT COUNT(*) FROM publication_reactions AS pr INNER JOIN publications AS rp ON pr.publicationId = rp.id
+			WHERE pr.userId = ? AND rp.authorId = p.authorId) AS affinity,
+		p.authorId NOT IN (SELECT f.userId FROM followers AS f WHERE f.followerId = ?) AS secondDegree
+		FROM publications AS p INNER JOIN users AS u ON p.authorId = u.id
+		WHERE p.createdAt >= ? AND p.authorId <> ? AND `+publishedClause+` AND `+notExpiredClause+` AND `+threadHeadClause+`
+		AND (
+			p.authorId IN (SELECT f.userId FROM followers AS f WHERE f.followerId = ?)
+			OR (u.private = false AND p.authorId IN (
+				SELECT second.userId FROM followers AS first
+				INNER JOIN followers AS second ON second.followerId = first.userId
+				WHERE first.followerId = ?
+			))
+		)
+		AND `+notBlockedClause("p.authorId")+` AND `+notMutedClause("p.authorId")+`
+		AND `+visibleClause("p")+`
+		ORDER BY p.createdAt DESC, p.id DESC LIMIT ?`,
+		id, id, since, id, id, id, id, id, id, id, id, id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []ranking.Candidate
+	for rows.Next() {
+		var candidate ranking.Candidate
+
+		if err = rows.Scan(
+			&candidate.PublicationID,
+			&candidate.CreatedAt,
+			&candidate.Reactions,
+			&candidate.Quotes,
+			&candidate.Affinity,
+			&candidate.SecondDegree,
+		); err != nil {
+			return nil, err
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// IsPrivate verifica se a conta do usuário é privada
+func (repo Users) IsPrivate(id uint64) (bool, error) {
+	row, err := repo.db.Query("SELECT private FROM users WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	defer row.Close()
+
+	var private bool
+	if row.Next() {
+		if err = row.Scan(&private); err != nil {
+			return false, err
+		}
+	}
+
+	return private, nil
+}
+
+// IsFollowing verifica se um usuário segue outro
+func (repo Users) IsFollowing(id, followedID uint64) (bool, error) {
+	row, err := repo.db.Query("SELECT 1 FROM followers WHERE userId = ? AND followerId = ?", followedID, id)
+	if err != nil {
+		return false, err
+	}
+	defer row.Close()
+
+	return row.Next(), row.Err()
+}
+
+// RequestFollow cria uma solicitação para seguir uma conta privada
+func (repo Users) RequestFollow(id, followedID uint64) error {
+	statement, err := repo.db.Prepare("INSERT ignore INTO follow_requests (userId, requesterId) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(followedID, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAllFollowRequests retorna uma página dos usuários que solicitaram seguir o usuário
+func (repo Users) GetAllFollowRequests(id uint64, page pagination.Page) ([]model.User, string, error) {
+	after, order, cursorArgs := pageClauses("fr.createdAt", "u.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT `+userColumns+`, fr.createdAt FROM follow_requests AS fr
+		INNER JOIN users AS u ON fr.requesterId = u.id WHERE fr.userId = ?`+after+order,
+		append([]interface{}{id}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	return scanUserPage(rows, page)
+}
+
+// ApproveFollowRequest aprova uma solicitação pendente, tornando o solicitante um seguidor.
+// Retorna sql.ErrNoRows caso a solicitação não exista
+func (repo Users) ApproveFollowRequest(id, requesterID uint64) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM follow_requests WHERE userId = ? AND requesterId = ?", id, requesterID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err = tx.Exec("INSERT ignore INTO followers (userId, followerId) VALUES (?, ?)", id, requesterID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RejectFollowRequest recusa uma solicitação pendente.
+// Retorna sql.ErrNoRows caso a solicitação não exista
+func (repo Users) RejectFollowRequest(id, requesterID uint64) error {
+	result, err := repo.db.Exec("DELETE FROM follow_requests WHERE userId = ? AND requesterId = ?", id, requesterID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
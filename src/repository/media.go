@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"api.devbook/src/model"
+)
+
+// Media representa um repositório de mídias enviadas pelos usuários
+type Media struct {
+	db *sql.DB
+}
+
+// NewRepositoryOfMedia cria um repositório de mídias
+func NewRepositoryOfMedia(db *sql.DB) *Media {
+	return &Media{db}
+}
+
+// Create registra uma mídia no banco de dados
+func (repo Media) Create(media model.Media) (uint64, error) {
+	statement, err := repo.db.Prepare(
+		`INSERT INTO media (ownerId, purpose, storageKey, contentType, width, height, size)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer statement.Close()
+
+	result, err := statement.Exec(
+		media.OwnerID,
+		media.Purpose,
+		media.Key,
+		media.ContentType,
+		media.Width,
+		media.Height,
+		media.Size,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	mediaID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(mediaID), nil
+}
+
+// GetByID traz a mídia com base no id fornecido
+func (repo Media) GetByID(mediaID uint64) (model.Media, error) {
+	row, err := repo.db.Query(
+		`SELECT id, ownerId, purpose, storageKey, contentType, width, height, size, createdAt
+		FROM media WHERE id = ?`,
+		mediaID,
+	)
+	if err != nil {
+		return model.Media{}, err
+	}
+	defer row.Close()
+
+	var media model.Media
+	if row.Next() {
+		if err = row.Scan(
+			&media.ID,
+			&media.OwnerID,
+			&media.Purpose,
+			&media.Key,
+			&media.ContentType,
+			&media.Width,
+			&media.Height,
+			&media.Size,
+			&media.CreatedAt,
+		); err != nil {
+			return model.Media{}, err
+		}
+	}
+
+	return media, nil
+}
+
+// Delete exclui o registro de uma mídia do banco de dados
+func (repo Media) Delete(mediaID uint64) error {
+	statement, err := repo.db.Prepare("DELETE FROM media WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(mediaID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAttachable retorna, entre as mídias informadas, as que podem ser anexadas à publicação: as que
+// pertencem ao autor, foram enviadas como anexo e não estão anexadas a outra publicação
+func (repo Media) GetAttachable(ownerID, publicationID uint64, mediaIDs []uint64) (map[uint64]model.Media, error) {
+	attachable := make(map[uint64]model.Media, len(mediaIDs))
+	if len(mediaIDs) == 0 {
+		return attachable, nil
+	}
+
+	args := []interface{}{ownerID, model.MediaAttachment, publicationID}
+	for _, mediaID := range mediaIDs {
+		args = append(args, mediaID)
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT m.id, m.storageKey, m.contentType, m.width, m.height FROM media AS m
+		LEFT JOIN publication_media AS pm ON pm.mediaId = m.id
+		WHERE m.ownerId = ? AND m.purpose = ? AND (pm.publicationId IS NULL OR pm.publicationId = ?)
+		AND m.id IN (`+placeholders(len(mediaIDs))+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var media model.Media
+
+		if err = rows.Scan(&media.ID, &media.Key, &media.ContentType, &media.Width, &media.Height); err != nil {
+			return nil, err
+		}
+
+		attachable[media.ID] = media
+	}
+
+	return attachable, rows.Err()
+}
+
+// GetOrphans retorna as mídias enviadas como anexo antes do momento informado que não estão
+// anexadas a nenhuma publicação
+func (repo Media) GetOrphans(before time.Time) ([]model.Media, error) {
+	rows, err := repo.db.Query(
+		`SELECT m.id, m.storageKey FROM media AS m
+		LEFT JOIN publication_media AS pm ON pm.mediaId = m.id
+		WHERE m.purpose = ? AND pm.mediaId IS NULL AND m.createdAt < ?`,
+		model.MediaAttachment, before,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orphans []model.Media
+	for rows.Next() {
+		var media model.Media
+
+		if err = rows.Scan(&media.ID, &media.Key); err != nil {
+			return nil, err
+		}
+
+		orphans = append(orphans, media)
+	}
+
+	return orphans, rows.Err()
+}
+
+// GetProfileImage retorna a imagem de perfil do usuário com a finalidade informada, buscando pelo id
+// ou pela chave de armazenamento em reference. A mídia vem vazia quando não há uma imagem do usuário
+// com essa finalidade
+func (repo Media) GetProfileImage(ownerID uint64, purpose, reference string) (model.Media, error) {
+	// Uma chave não numérica não corresponde a nenhum id
+	mediaID, _ := strconv.ParseUint(reference, 10, 64)
+
+	row, err := repo.db.Query(
+		`SELECT id, storageKey FROM media WHERE ownerId = ? AND purpose = ? AND (id = ? OR storageKey = ?)`,
+		ownerID, purpose, mediaID, reference,
+	)
+	if err != nil {
+		return model.Media{}, err
+	}
+	defer row.Close()
+
+	var media model.Media
+	if row.Next() {
+		if err = row.Scan(&media.ID, &media.Key); err != nil {
+			return model.Media{}, err
+		}
+	}
+
+	return media, row.Err()
+}
+
+// GetReplacedProfileImages retorna as imagens de perfil do usuário com a finalidade informada, exceto
+// a atual, que foram substituídas e podem ser excluídas. Sem uma imagem atual, todas são retornadas
+func (repo Media) GetReplacedProfileImages(ownerID uint64, purpose string, currentID uint64) ([]model.Media, error) {
+	rows, err := repo.db.Query(
+		"SELECT id, storageKey FROM media WHERE ownerId = ? AND purpose = ? AND id <> ?",
+		ownerID, purpose, currentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var replaced []model.Media
+	for rows.Next() {
+		var media model.Media
+
+		if err = rows.Scan(&media.ID, &media.Key); err != nil {
+			return nil, err
+		}
+
+		replaced = append(replaced, media)
+	}
+
+	return replaced, rows.Err()
+}
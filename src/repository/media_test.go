@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"api.devbook/src/model"
+)
+
+// createTestMedia registra uma mídia do usuário com a finalidade informada, sem arquivo no
+// armazenamento
+func createTestMedia(t *testing.T, db *sql.DB, ownerID uint64, purpose string) model.Media {
+	t.Helper()
+
+	media := model.Media{
+		OwnerID:     ownerID,
+		Purpose:     purpose,
+		Key:         fmt.Sprintf("teste-%d.png", time.Now().UnixNano()),
+		ContentType: "image/png",
+		Width:       1,
+		Height:      1,
+		Size:        1,
+	}
+
+	id, err := NewRepositoryOfMedia(db).Create(media)
+	if err != nil {
+		t.Fatalf("criar mídia: %v", err)
+	}
+
+	media.ID = id
+	return media
+}
+
+func TestGetProfileImage(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewRepositoryOfMedia(db)
+
+	ownerID := createTestUser(t, db)
+	otherID := createTestUser(t, db)
+
+	avatar := createTestMedia(t, db, ownerID, model.MediaAvatar)
+	attachment := createTestMedia(t, db, ownerID, model.MediaAttachment)
+	othersAvatar := createTestMedia(t, db, otherID, model.MediaAvatar)
+
+	tests := []struct {
+		name      string
+		reference string
+		want      uint64
+	}{
+		{name: "pelo id", reference: strconv.FormatUint(avatar.ID, 10), want: avatar.ID},
+		{name: "pela chave", reference: avatar.Key, want: avatar.ID},
+		{name: "outra finalidade", reference: strconv.FormatUint(attachment.ID, 10)},
+		{name: "de outro usuário", reference: othersAvatar.Key},
+		{name: "endereço externo", reference: "https://example.com/avatar.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := repo.GetProfileImage(ownerID, model.MediaAvatar, tt.reference)
+			if err != nil {
+				t.Fatalf("GetProfileImage: %v", err)
+			}
+
+			if image.ID != tt.want {
+				t.Errorf("GetProfileImage(%q) = %d, want %d", tt.reference, image.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetReplacedProfileImages(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewRepositoryOfMedia(db)
+
+	ownerID := createTestUser(t, db)
+
+	previous := createTestMedia(t, db, ownerID, model.MediaAvatar)
+	current := createTestMedia(t, db, ownerID, model.MediaAvatar)
+	createTestMedia(t, db, ownerID, model.MediaHeader)
+
+	replaced, err := repo.GetReplacedProfileImages(ownerID, model.MediaAvatar, current.ID)
+	if err != nil {
+		t.Fatalf("GetReplacedProfileImages: %v", err)
+	}
+
+	if len(replaced) != 1 || replaced[0].ID != previous.ID || replaced[0].Key != previous.Key {
+		t.Errorf("substituídas = %+v, want apenas %d", replaced, previous.ID)
+	}
+
+	// Sem uma imagem atual, todas as da finalidade foram substituídas
+	if replaced, err = repo.GetReplacedProfileImages(ownerID, model.MediaAvatar, 0); err != nil || len(replaced) != 2 {
+		t.Errorf("GetReplacedProfileImages sem atual = %d mídias, %v, want 2", len(replaced), err)
+	}
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// Notifications representa um repositório de notificações
+type Notifications struct {
+	db *sql.DB
+}
+
+// NewRepositoryOfNotifications cria um repositório de notificações
+func NewRepositoryOfNotifications(db *sql.DB) *Notifications {
+	return &Notifications{db}
+}
+
+// Create cria uma notificação do mesmo tipo, do mesmo autor e sobre a mesma publicação para cada um
+// dos usuários informados. O autor nunca é notificado das próprias ações
+func (repo Notifications) Create(notification model.Notification, userIDs ...uint64) error {
+	var publicationID interface{}
+	if notification.PublicationID != 0 {
+		publicationID = notification.PublicationID
+	}
+
+	for _, userID := range userIDs {
+		if userID == notification.ActorID {
+			continue
+		}
+
+		if _, err := repo.db.Exec(
+			"INSERT INTO notifications (userId, actorId, type, publicationId) VALUES (?, ?, ?, ?)",
+			userID, notification.ActorID, notification.Type, publicationID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAll retorna uma página das notificações do usuário, das mais recentes para as mais antigas,
+// omitindo as causadas por usuários bloqueados ou silenciados. Com unreadOnly, apenas as não lidas
+// são retornadas
+func (repo Notifications) GetAll(userID uint64, unreadOnly bool, page pagination.Page) ([]model.Notification, string, error) {
+	after, order, cursorArgs := pageClauses("n.createdAt", "n.id", page)
+
+	unread := ""
+	if unreadOnly {
+		unread = " AND n.readAt IS NULL"
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT n.id, n.type, n.actorId, u.nick, COALESCE(n.publicationId, 0), n.readAt IS NOT NULL, n.createdAt
+		FROM notifications AS n INNER JOIN users AS u ON n.actorId = u.id
+		WHERE n.userId = ? AND `+notBlockedClause("n.actorId")+` AND `+notMutedClause("n.actorId")+
+			unread+after+order,
+		append([]interface{}{userID, userID, userID, userID}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var (
+		notifications []model.Notification
+		keys          []pagination.Cursor
+	)
+
+	for rows.Next() {
+		var notification model.Notification
+		if err = rows.Scan(
+			&notification.ID,
+			&notification.Type,
+			&notification.ActorID,
+			&notification.ActorNick,
+			&notification.PublicationID,
+			&notification.Read,
+			&notification.CreatedAt,
+		); err != nil {
+			return nil, "", err
+		}
+
+		notifications = append(notifications, notification)
+		keys = append(keys, pagination.Cursor{CreatedAt: notification.CreatedAt, ID: notification.ID})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	notifications, next := pagination.Trim(notifications, keys, page.Limit)
+	return notifications, next, nil
+}
+
+// MarkAsRead marca a notificação do usuário como lida, retornando sql.ErrNoRows caso ela não exista
+func (repo Notifications) MarkAsRead(userID, notificationID uint64) error {
+	result, err := repo.db.Exec(
+		"UPDATE notifications SET readAt = COALESCE(readAt, current_timestamp()) WHERE id = ? AND userId = ?",
+		notificationID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// MarkAllAsRead marca todas as notificações do usuário como lidas
+func (repo Notifications) MarkAllAsRead(userID uint64) error {
+	_, err := repo.db.Exec(
+		"UPDATE notifications SET readAt = current_timestamp() WHERE userId = ? AND readAt IS NULL",
+		userID,
+	)
+	return err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// userColumns são as colunas lidas por scanUserPage, que espera ainda uma última coluna com a data
+// usada como chave da paginação
+const userColumns = "u.id, u.name, u.nick, u.email, u.private, u.avatar, u.createdAt"
+
+// pageClauses retorna a condição que seleciona os itens posteriores ao cursor da página, a ser
+// adicionada ao WHERE, e a ordenação com o limite da consulta. Um item além do limite é buscado para
+// saber se existe uma próxima página
+func pageClauses(createdAtColumn, idColumn string, page pagination.Page) (string, string, []interface{}) {
+	order := fmt.Sprintf(" ORDER BY %s DESC, %s DESC LIMIT %d", createdAtColumn, idColumn, page.Limit+1)
+
+	if page.After == nil {
+		return "", order, nil
+	}
+
+	where := fmt.Sprintf(" AND (%[1]s < ? OR (%[1]s = ? AND %[2]s < ?))", createdAtColumn, idColumn)
+	return where, order, []interface{}{page.After.CreatedAt, page.After.CreatedAt, page.After.ID}
+}
+
+// scanUserPage lê os usuários selecionados com userColumns seguidas da data usada como chave da
+// paginação e retorna a página junto com o cursor da próxima
+func scanUserPage(rows *sql.Rows, page pagination.Page) ([]model.User, string, error) {
+	var (
+		users []model.User
+		keys  []pagination.Cursor
+	)
+
+	for rows.Next() {
+		var (
+			user model.User
+			key  time.Time
+		)
+
+		if err := rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Nick,
+			&user.Email,
+			&user.Private,
+			&user.Avatar,
+			&user.CreatedAt,
+			&key,
+		); err != nil {
+			return nil, "", err
+		}
+
+		users = append(users, user)
+		keys = append(keys, pagination.Cursor{CreatedAt: key, ID: user.ID})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	users, next := pagination.Trim(users, keys, page.Limit)
+	return users, next, nil
+}
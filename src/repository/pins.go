@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"api.devbook/src/model"
+)
+
+// ErrTooManyPinned é retornado ao fixar uma publicação quando o autor já atingiu o limite de
+// publicações fixadas
+var ErrTooManyPinned = fmt.Errorf("É permitido fixar no máximo %d publicações", model.MaxPinned)
+
+// ErrNotPinnable é retornado ao fixar um repost, um rascunho ou uma publicação agendada
+var ErrNotPinnable = errors.New("Apenas publicações próprias já publicadas podem ser fixadas")
+
+// Pin fixa a publicação no perfil do autor. Fixar uma publicação que já está fixada não tem efeito
+func (repo Publications) Pin(authorID, publicationID uint64) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A linha do autor é bloqueada para que fixações simultâneas não ultrapassem o limite
+	var locked uint64
+	if err = tx.QueryRow("SELECT id FROM users WHERE id = ? FOR UPDATE", authorID).Scan(&locked); err != nil {
+		return err
+	}
+
+	var (
+		status   string
+		repostOf uint64
+		pinned   bool
+	)
+
+	if err = tx.QueryRow(
+		"SELECT status, COALESCE(repostOfId, 0), pinnedAt IS NOT NULL FROM publications WHERE id = ? AND authorId = ?",
+		publicationID, authorID,
+	).Scan(&status, &repostOf, &pinned); err != nil {
+		return err
+	}
+
+	if status != model.StatusPublished || repostOf != 0 {
+		return ErrNotPinnable
+	}
+
+	if pinned {
+		return nil
+	}
+
+	var count int
+	if err = tx.QueryRow(
+		"SELECT COUNT(*) FROM publications WHERE authorId = ? AND pinnedAt IS NOT NULL", authorID,
+	).Scan(&count); err != nil {
+		return err
+	}
+
+	if count >= model.MaxPinned {
+		return ErrTooManyPinned
+	}
+
+	if _, err = tx.Exec("UPDATE publications SET pinnedAt = current_timestamp() WHERE id = ?", publicationID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Unpin desafixa a publicação do perfil do autor
+func (repo Publications) Unpin(publicationID uint64) error {
+	_, err := repo.db.Exec("UPDATE publications SET pinnedAt = NULL WHERE id = ?", publicationID)
+	return err
+}
+
+// GetPinned retorna as publicações fixadas no perfil do autor que a visibilidade permite que o
+// usuário que está consultando veja, das fixadas mais recentemente para as mais antigas
+func (repo Publications) GetPinned(authorID uint64) ([]model.Publication, error) {
+	rows, err := repo.db.Query(
+		`SELECT `+publicationColumns+` FROM publications AS p
+		INNER JOIN users AS u ON p.authorId = u.id
+		WHERE p.authorId = ? AND p.pinnedAt IS NOT NULL AND `+publishedClause+` AND `+notExpiredClause+`
+		AND `+visibleClause("p")+`
+		ORDER BY p.pinnedAt DESC, p.id DESC`,
+		authorID, repo.viewerID, repo.viewerID, repo.viewerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return repo.scanPublications(rows)
+}
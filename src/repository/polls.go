@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"api.devbook/src/model"
+)
+
+var (
+	// ErrPollClosed é retornado ao votar em uma enquete já encerrada
+	ErrPollClosed = errors.New("A enquete já foi encerrada")
+	// ErrPollNotPublished é retornado ao votar na enquete de um rascunho ou de uma publicação agendada
+	ErrPollNotPublished = errors.New("A enquete só recebe votos depois que a publicação for publicada")
+	// ErrAlreadyVoted é retornado quando o usuário já votou na enquete
+	ErrAlreadyVoted = errors.New("Você já votou nesta enquete")
+	// ErrInvalidVote é retornado quando as opções escolhidas não pertencem à enquete ou quando mais de
+	// uma opção é escolhida em uma enquete de escolha única
+	ErrInvalidVote = errors.New("As opções escolhidas não são válidas para esta enquete")
+)
+
+// Vote registra o voto do usuário na enquete da publicação, ou retorna sql.ErrNoRows caso ela não
+// tenha enquete. Enquetes de publicações que ainda não foram publicadas não recebem votos. O registro
+// do votante e das opções escolhidas acontece na mesma transação, e a chave primária dos votantes
+// garante um único voto por usuário mesmo com votos simultâneos
+func (repo Publications) Vote(publicationID, userID uint64, optionIDs []uint64) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var multiple, open, published bool
+	if err = tx.QueryRow(
+		`SELECT pl.multiple, pl.closesAt > current_timestamp(), `+publishedClause+`
+		FROM polls AS pl INNER JOIN publications AS p ON pl.publicationId = p.id WHERE pl.publicationId = ?`,
+		publicationID,
+	).Scan(&multiple, &open, &published); err != nil {
+		return err
+	}
+
+	if !published {
+		return ErrPollNotPublished
+	}
+
+	if !open {
+		return ErrPollClosed
+	}
+
+	if !multiple && len(optionIDs) > 1 {
+		return ErrInvalidVote
+	}
+
+	args := make([]interface{}, 0, len(optionIDs)+1)
+	args = append(args, publicationID)
+	for _, optionID := range optionIDs {
+		args = append(args, optionID)
+	}
+
+	var count int
+	if err = tx.QueryRow(
+		"SELECT COUNT(*) FROM poll_options WHERE publicationId = ? AND id IN ("+placeholders(len(optionIDs))+")",
+		args...,
+	).Scan(&count); err != nil {
+		return err
+	}
+
+	if count != len(optionIDs) {
+		return ErrInvalidVote
+	}
+
+	result, err := tx.Exec(
+		"INSERT ignore INTO poll_voters (publicationId, userId) VALUES (?, ?)", publicationID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrAlreadyVoted
+	}
+
+	for _, optionID := range optionIDs {
+		if _, err = tx.Exec(
+			"INSERT INTO poll_votes (optionId, publicationId, userId) VALUES (?, ?, ?)",
+			optionID, publicationID, userID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertPoll(tx *sql.Tx, publicationID uint64, poll *model.Poll) error {
+	if poll == nil {
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO polls (publicationId, multiple, closesAt) VALUES (?, ?, ?)",
+		publicationID, poll.Multiple, poll.ClosesAt,
+	); err != nil {
+		return err
+	}
+
+	for position, option := range poll.Options {
+		if _, err := tx.Exec(
+			"INSERT INTO poll_options (publicationId, position, text) VALUES (?, ?, ?)",
+			publicationID, position, option.Text,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPolls busca as enquetes das publicações informadas. Os votos são sempre contados a partir dos
+// votos gravados, e só são preenchidos quando o usuário que está consultando já votou ou quando a
+// enquete foi encerrada
+func (repo Publications) loadPolls(publications []model.Publication) error {
+	if len(publications) == 0 {
+		return nil
+	}
+
+	indexes := make(map[uint64][]int, len(publications))
+	args := make([]interface{}, 0, len(publications)+1)
+	for i, publication := range publications {
+		indexes[publication.ID] = append(indexes[publication.ID], i)
+		args = append(args, publication.ID)
+	}
+
+	in := "(" + placeholders(len(publications)) + ")"
+
+	polls := make(map[uint64]*model.Poll)
+	if err := repo.queryPolls(
+		`SELECT pl.publicationId, pl.multiple, pl.closesAt,
+		(SELECT COUNT(*) FROM poll_voters AS v WHERE v.publicationId = pl.publicationId)
+		FROM polls AS pl WHERE pl.publicationId IN `+in,
+		args,
+		func(rows *sql.Rows) error {
+			var (
+				publicationID uint64
+				poll          model.Poll
+			)
+
+			if err := rows.Scan(&publicationID, &poll.Multiple, &poll.ClosesAt, &poll.Voters); err != nil {
+				return err
+			}
+
+			poll.Closed = !poll.ClosesAt.After(time.Now())
+			polls[publicationID] = &poll
+			return nil
+		},
+	); err != nil || len(polls) == 0 {
+		return err
+	}
+
+	if err := repo.queryPolls(
+		`SELECT o.publicationId, o.id, o.text, (SELECT COUNT(*) FROM poll_votes AS pv WHERE pv.optionId = o.id)
+		FROM poll_options AS o WHERE o.publicationId IN `+in+` ORDER BY o.publicationId, o.position`,
+		args,
+		func(rows *sql.Rows) error {
+			var (
+				publicationID uint64
+				option        model.PollOption
+				votes         uint64
+			)
+
+			if err := rows.Scan(&publicationID, &option.ID, &option.Text, &votes); err != nil {
+				return err
+			}
+
+			option.Votes = &votes
+			poll := polls[publicationID]
+			poll.Options = append(poll.Options, option)
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+
+	if repo.viewerID != 0 {
+		if err := repo.queryPolls(
+			"SELECT publicationId, optionId FROM poll_votes WHERE userId = ? AND publicationId IN "+in,
+			append([]interface{}{repo.viewerID}, args...),
+			func(rows *sql.Rows) error {
+				var publicationID, optionID uint64
+				if err := rows.Scan(&publicationID, &optionID); err != nil {
+					return err
+				}
+
+				poll := polls[publicationID]
+				poll.MyVotes = append(poll.MyVotes, optionID)
+				return nil
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	for publicationID, poll := range polls {
+		if !poll.Closed && len(poll.MyVotes) == 0 {
+			poll.ResultsHidden = true
+			for i := range poll.Options {
+				poll.Options[i].Votes = nil
+			}
+		}
+
+		for _, i := range indexes[publicationID] {
+			publications[i].Poll = poll
+		}
+	}
+
+	return nil
+}
+
+// queryPolls executa a consulta e chama scan para cada linha retornada
+func (repo Publications) queryPolls(query string, args []interface{}, scan func(*sql.Rows) error) error {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"api.devbook/src/model"
+)
+
+func newTestPoll(multiple bool) *model.Poll {
+	return &model.Poll{
+		Options:  []model.PollOption{{Text: "A"}, {Text: "B"}, {Text: "C"}},
+		Multiple: multiple,
+		ClosesAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestVoteConcurrently(t *testing.T) {
+	db := openTestDB(t)
+
+	const (
+		voters   = 20
+		attempts = 3
+	)
+
+	tests := []struct {
+		name     string
+		multiple bool
+		// choices retorna as opções escolhidas pelo votante em cada tentativa
+		choices func(options []uint64, attempt int) []uint64
+	}{
+		{
+			name: "escolha única",
+			choices: func(options []uint64, attempt int) []uint64 {
+				return []uint64{options[attempt%len(options)]}
+			},
+		},
+		{
+			name:     "múltipla escolha",
+			multiple: true,
+			choices: func(options []uint64, attempt int) []uint64 {
+				return []uint64{options[0], options[1+attempt%2]}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorID := createTestUser(t, db)
+			publicationID := createTestPublication(t, db, authorID, model.Publication{Poll: newTestPoll(tt.multiple)})
+
+			repo := NewRepositoryOfPublications(db)
+
+			publication, err := repo.GetById(publicationID)
+			if err != nil || publication.Poll == nil {
+				t.Fatalf("GetById: %v", err)
+			}
+
+			var options []uint64
+			for _, option := range publication.Poll.Options {
+				options = append(options, option.ID)
+			}
+
+			userIDs := make([]uint64, voters)
+			for i := range userIDs {
+				userIDs[i] = createTestUser(t, db)
+			}
+
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				accepted = make(map[uint64][]uint64)
+			)
+
+			for _, userID := range userIDs {
+				for attempt := 0; attempt < attempts; attempt++ {
+					wg.Add(1)
+					go func(userID uint64, choice []uint64) {
+						defer wg.Done()
+
+						err := repo.Vote(publicationID, userID, choice)
+						switch {
+						case err == nil:
+							mu.Lock()
+							accepted[userID] = append(accepted[userID], choice...)
+							mu.Unlock()
+						case !errors.Is(err, ErrAlreadyVoted):
+							t.Errorf("Vote(%d, %v): %v", userID, choice, err)
+						}
+					}(userID, tt.choices(options, attempt))
+				}
+			}
+			wg.Wait()
+
+			want := make(map[uint64]uint64)
+			for _, userID := range userIDs {
+				chosen := 1
+				if tt.multiple {
+					chosen = 2
+				}
+				if len(accepted[userID]) != chosen {
+					t.Errorf("usuário %d teve %d opções aceitas, want %d", userID, len(accepted[userID]), chosen)
+				}
+
+				for _, optionID := range accepted[userID] {
+					want[optionID]++
+				}
+			}
+
+			// Os resultados são exibidos para quem já votou
+			publication, err = repo.WithViewer(userIDs[0]).GetById(publicationID)
+			if err != nil {
+				t.Fatalf("GetById: %v", err)
+			}
+
+			if publication.Poll.Voters != voters {
+				t.Errorf("Voters = %d, want %d", publication.Poll.Voters, voters)
+			}
+
+			for _, option := range publication.Poll.Options {
+				if option.Votes == nil {
+					t.Fatalf("opção %q sem votos para quem votou", option.Text)
+				}
+
+				if *option.Votes != want[option.ID] {
+					t.Errorf("opção %q: %d votos, want %d", option.Text, *option.Votes, want[option.ID])
+				}
+			}
+		})
+	}
+}
+
+func TestVoteOnUnpublished(t *testing.T) {
+	db := openTestDB(t)
+
+	authorID := createTestUser(t, db)
+	voterID := createTestUser(t, db)
+
+	for _, status := range []string{model.StatusDraft, model.StatusScheduled} {
+		publication := model.Publication{Status: status, Poll: newTestPoll(false)}
+		if status == model.StatusScheduled {
+			publishAt := time.Now().Add(time.Minute)
+			publication.PublishAt = &publishAt
+		}
+
+		publicationID := createTestPublication(t, db, authorID, publication)
+
+		repo := NewRepositoryOfPublications(db)
+
+		stored, err := repo.GetById(publicationID)
+		if err != nil || stored.Poll == nil {
+			t.Fatalf("GetById: %v", err)
+		}
+
+		err = repo.Vote(publicationID, voterID, []uint64{stored.Poll.Options[0].ID})
+		if !errors.Is(err, ErrPollNotPublished) {
+			t.Errorf("%s: Vote = %v, want ErrPollNotPublished", status, err)
+		}
+	}
+}
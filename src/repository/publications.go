@@ -2,30 +2,102 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"strings"
 
+	"api.devbook/src/markdown"
+	"api.devbook/src/media"
 	"api.devbook/src/model"
+	"api.devbook/src/pagination"
 )
 
+// publicationColumns são as colunas lidas por scanPublicationRows, na mesma ordem
+const publicationColumns = `p.id, p.title, p.content, p.authorId, p.reposts, p.quotes,
+	COALESCE(p.repostOfId, 0), COALESCE(p.quotedId, 0), p.status, p.visibility, p.publishAt, p.editedAt,
+	p.pinnedAt IS NOT NULL, COALESCE(p.threadId, 0), p.threadPosition, p.expiresAt, p.createdAt, u.nick`
+
+// publishedClause restringe a consulta às publicações já publicadas, excluindo rascunhos e agendadas
+const publishedClause = "p.status = '" + model.StatusPublished + "'"
+
+// ErrAlreadyPublished é retornado ao tentar transformar uma publicação já publicada em rascunho ou
+// agendada
+var ErrAlreadyPublished = errors.New("Publicações já publicadas não podem voltar a ser rascunho ou agendadas")
+
+// ErrAlreadyReposted é retornado ao repostar uma publicação que o usuário já repostou, inclusive quando
+// dois reposts são criados ao mesmo tempo
+var ErrAlreadyReposted = errors.New("Você já repostou esta publicação")
+
 // Publications representa um repositório de publicações
 type Publications struct {
-	db *sql.DB
+	db       *sql.DB
+	viewerID uint64
 }
 
 // NewRepositoryOfPublications cria um repositório de publicações
 func NewRepositoryOfPublications(db *sql.DB) *Publications {
-	return &Publications{db}
+	return &Publications{db: db}
+}
+
+// WithViewer retorna uma cópia do repositório que carrega as publicações do ponto de vista do usuário
+// informado. Sem um usuário, as publicações são carregadas sem considerar quem está consultando
+func (repo Publications) WithViewer(viewerID uint64) *Publications {
+	repo.viewerID = viewerID
+	return &repo
 }
 
-// Create cria uma publicação no banco de dados
+// Create cria uma publicação no banco de dados junto com os seus anexos, a sua enquete e as partes
+// seguintes da thread, quando houver. Para reposts e citações, o contador correspondente da publicação
+// original é incrementado. Retorna o id da publicação, que é a primeira parte da thread
 func (repo Publications) Create(publication model.Publication) (uint64, error) {
-	statement, err := repo.db.Prepare("INSERT INTO publications (title, content, authorId) VALUES (?, ?, ?)")
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	publicationID, err := insertPublication(tx, publication)
 	if err != nil {
 		return 0, err
 	}
-	defer statement.Close()
 
-	result, err := statement.Exec(publication.Title, publication.Content, publication.AuthorID)
+	for i, part := range publication.Thread {
+		part.ThreadID = publicationID
+		part.ThreadPosition = i + 2
+
+		if _, err = insertPublication(tx, part); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return publicationID, nil
+}
+
+// insertPublication grava a publicação e os dados relacionados a ela na transação
+func insertPublication(tx *sql.Tx, publication model.Publication) (uint64, error) {
+	position := publication.ThreadPosition
+	if position == 0 {
+		position = 1
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO publications
+		(title, content, authorId, repostOfId, quotedId, status, visibility, publishAt, expiresAt, threadId, threadPosition)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		publication.Title, publication.Content, publication.AuthorID,
+		nullableID(publication.RepostOfID), nullableID(publication.QuotedID),
+		statusOrPublished(publication.Status), visibilityOrPublic(publication.Visibility), publication.PublishAt,
+		publication.ExpiresAt, nullableID(publication.ThreadID), position,
+	)
 	if err != nil {
+		// A chave única de autor e original impede reposts repetidos criados ao mesmo tempo
+		if publication.RepostOfID != 0 && isMySQLError(err, errDuplicateEntry) {
+			return 0, ErrAlreadyReposted
+		}
+
 		return 0, err
 	}
 
@@ -34,171 +106,770 @@ func (repo Publications) Create(publication model.Publication) (uint64, error) {
 		return 0, err
 	}
 
+	if publication.RepostOfID != 0 {
+		if _, err = tx.Exec(
+			"UPDATE publications SET reposts = reposts + 1 WHERE id = ?", publication.RepostOfID,
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	// Rascunhos e publicações agendadas só contam como citação quando forem publicados
+	if publication.QuotedID != 0 && statusOrPublished(publication.Status) == model.StatusPublished {
+		if _, err = tx.Exec(
+			"UPDATE publications SET quotes = quotes + 1 WHERE id = ?", publication.QuotedID,
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = insertAttachments(tx, uint64(publicationID), publication.Attachments); err != nil {
+		return 0, err
+	}
+
+	if err = saveTags(tx, uint64(publicationID), publication.Tags); err != nil {
+		return 0, err
+	}
+
+	if err = saveMentions(tx, uint64(publicationID), publication.Mentions); err != nil {
+		return 0, err
+	}
+
+	if err = insertPoll(tx, uint64(publicationID), publication.Poll); err != nil {
+		return 0, err
+	}
+
 	return uint64(publicationID), nil
 }
 
-// GetById traz a publicação com base no id fornecido
+// GetById traz a publicação com base no id fornecido. Publicações publicadas que expiraram não são
+// encontradas
 func (repo Publications) GetById(publicationID uint64) (model.Publication, error) {
-	row, err := repo.db.Query(
-		"SELECT p.*, u.nick FROM publications AS p INNER JOIN users AS u ON P.authorId = u.id WHERE p.id = ?",
+	rows, err := repo.db.Query(
+		`SELECT `+publicationColumns+` FROM publications AS p INNER JOIN users AS u ON p.authorId = u.id
+		WHERE p.id = ? AND `+notExpiredClause,
 		publicationID,
 	)
-
 	if err != nil {
 		return model.Publication{}, err
 	}
+	defer rows.Close()
+
+	publications, err := repo.scanPublications(rows)
+	if err != nil || len(publications) == 0 {
+		return model.Publication{}, err
+	}
+
+	return publications[0], nil
+}
+
+// Update atualiza uma publicação no banco de dados, substituindo os seus anexos, as suas hashtags e as
+// suas menções, e retorna verdadeiro quando a atualização publicou um rascunho ou uma publicação
+// agendada. A data de criação de uma publicação passa a ser o momento em que ela foi publicada. Ao
+// editar uma publicação já publicada, o título e o conteúdo anteriores são guardados como uma revisão
+// e a data da edição é registrada
+func (repo Publications) Update(publicationID uint64, publication model.Publication) (bool, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// A linha é bloqueada para não concorrer com a publicação das agendadas
+	var current model.Publication
+	if err = tx.QueryRow(
+		"SELECT title, content, status FROM publications WHERE id = ? FOR UPDATE", publicationID,
+	).Scan(&current.Title, &current.Content, &current.Status); err != nil {
+		return false, err
+	}
+
+	status := statusOrPublished(publication.Status)
+	if current.Status == model.StatusPublished && status != model.StatusPublished {
+		return false, ErrAlreadyPublished
+	}
+
+	published := current.Status != model.StatusPublished && status == model.StatusPublished
+
+	createdAt, editedAt := "createdAt", "editedAt"
+	switch {
+	case published:
+		createdAt = "current_timestamp()"
+	case current.Status == model.StatusPublished:
+		editedAt = "current_timestamp()"
+
+		if current.Title != publication.Title || current.Content != publication.Content {
+			if _, err = tx.Exec(
+				"INSERT INTO publication_revisions (publicationId, title, content) VALUES (?, ?, ?)",
+				publicationID, current.Title, current.Content,
+			); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if _, err = tx.Exec(
+		`UPDATE publications SET title = ?, content = ?, status = ?, visibility = ?, publishAt = ?,
+		createdAt = `+createdAt+`, editedAt = `+editedAt+` WHERE id = ?`,
+		publication.Title, publication.Content, status, visibilityOrPublic(publication.Visibility),
+		publication.PublishAt, publicationID,
+	); err != nil {
+		return false, err
+	}
+
+	// As partes seguintes de uma thread acompanham a visibilidade da primeira
+	if _, err = tx.Exec(
+		"UPDATE publications SET visibility = ? WHERE threadId = ?",
+		visibilityOrPublic(publication.Visibility), publicationID,
+	); err != nil {
+		return false, err
+	}
+
+	if _, err = tx.Exec("DELETE FROM publication_media WHERE publicationId = ?", publicationID); err != nil {
+		return false, err
+	}
+
+	if err = insertAttachments(tx, publicationID, publication.Attachments); err != nil {
+		return false, err
+	}
+
+	if err = saveTags(tx, publicationID, publication.Tags); err != nil {
+		return false, err
+	}
+
+	if err = saveMentions(tx, publicationID, publication.Mentions); err != nil {
+		return false, err
+	}
+
+	if published {
+		if err = onPublished(tx, publicationID); err != nil {
+			return false, err
+		}
+	}
+
+	return published, tx.Commit()
+}
+
+// Delete exclui uma publicação do banco de dados junto com as mídias anexadas e os seus reposts,
+// decrementando os contadores da publicação original quando ela é um repost ou uma citação. As
+// citações desta publicação são mantidas e passam a indicar que a original não está disponível.
+// Excluir a primeira parte de uma thread exclui a thread inteira, e excluir uma das partes seguintes
+// renumera as que vêm depois dela. Os arquivos das mídias devem ser removidos do armazenamento por
+// quem chama
+func (repo Publications) Delete(publicationID uint64) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var threadID, position uint64
+	if err = tx.QueryRow(
+		"SELECT COALESCE(threadId, 0), threadPosition FROM publications WHERE id = ?", publicationID,
+	).Scan(&threadID, &position); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("SELECT id FROM publications WHERE threadId = ?", publicationID)
+	if err != nil {
+		return err
+	}
+
+	partIDs, err := scanIDs(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	// As partes são excluídas antes da primeira, que as excluiria em cascata sem atualizar os contadores
+	for _, id := range append(partIDs, publicationID) {
+		if err = deletePublication(tx, id); err != nil {
+			return err
+		}
+	}
+
+	if threadID != 0 {
+		if _, err = tx.Exec(
+			`UPDATE publications SET threadPosition = threadPosition - 1
+			WHERE threadId = ? AND threadPosition > ? ORDER BY threadPosition`,
+			threadID, position,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// deletePublication exclui a publicação na transação, atualizando os contadores das originais
+func deletePublication(tx *sql.Tx, publicationID uint64) error {
+	if _, err := tx.Exec(
+		`UPDATE publications AS o INNER JOIN publications AS p ON p.repostOfId = o.id
+		SET o.reposts = o.reposts - 1 WHERE p.id = ? AND o.reposts > 0`,
+		publicationID,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE publications AS o INNER JOIN publications AS p ON p.quotedId = o.id
+		SET o.quotes = o.quotes - 1 WHERE p.id = ? AND p.status = '`+model.StatusPublished+`' AND o.quotes > 0`,
+		publicationID,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		`DELETE m FROM media AS m INNER JOIN publication_media AS pm ON pm.mediaId = m.id
+		WHERE pm.publicationId = ?`,
+		publicationID,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec("DELETE FROM publications WHERE id = ?", publicationID)
+	return err
+}
+
+// GetAllPublicationsOfUser retorna uma página das publicações de um usuário que a visibilidade permite
+// que o usuário que está consultando veja. As publicações fixadas ficam de fora, pois são retornadas
+// por GetPinned
+func (repo Publications) GetAllPublicationsOfUser(authorId uint64, page pagination.Page) ([]model.Publication, string, error) {
+	after, order, cursorArgs := pageClauses("p.createdAt", "p.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT `+publicationColumns+` FROM publications AS p
+		INNER JOIN users AS u ON p.authorId = u.id
+		WHERE p.authorId = ? AND p.pinnedAt IS NULL AND `+publishedClause+` AND `+notExpiredClause+`
+		AND `+visibleClause("p")+after+order,
+		append([]interface{}{authorId, repo.viewerID, repo.viewerID, repo.viewerID}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	return repo.scanPage(rows, page)
+}
+
+// GetRepostID retorna o id do repost feito pelo usuário da publicação original, ou zero caso ele
+// não a tenha repostado
+func (repo Publications) GetRepostID(userID, originalID uint64) (uint64, error) {
+	row, err := repo.db.Query(
+		"SELECT id FROM publications WHERE authorId = ? AND repostOfId = ?",
+		userID, originalID,
+	)
+	if err != nil {
+		return 0, err
+	}
 	defer row.Close()
 
-	var publication model.Publication
+	var repostID uint64
 	if row.Next() {
-		if err = row.Scan(
-			&publication.ID,
-			&publication.Title,
-			&publication.Content,
-			&publication.AuthorID,
-			&publication.Likes,
-			&publication.CreatedAt,
-			&publication.AuthorNick,
-		); err != nil {
-			return model.Publication{}, err
+		if err = row.Scan(&repostID); err != nil {
+			return 0, err
 		}
 	}
 
-	return publication, nil
+	return repostID, row.Err()
 }
 
-// GetAll retorna todas as publicações dos seguidores, dos usuários seguidos e as próprias publicações
-func (repo Publications) GetAll(id uint64) ([]model.Publication, error) {
+// GetReposts retorna o id e o autor dos reposts das publicações informadas, que são excluídos em
+// cascata junto com elas e também precisam ser retirados das linhas do tempo
+func (repo Publications) GetReposts(originalIDs []uint64) ([]model.Publication, error) {
+	if len(originalIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(originalIDs))
+	for _, id := range originalIDs {
+		args = append(args, id)
+	}
+
 	rows, err := repo.db.Query(
-		`SELECT DISTINCT p.*, u.nick FROM publications AS p INNER JOIN users AS u ON p.authorId = u.id
-		INNER JOIN followers AS f ON p.authorId = f.userId OR p.authorId = f.followerId
-		WHERE f.userId = ? OR f.followerId = ? ORDER BY p.id DESC`,
-		id,
-		id,
+		"SELECT id, authorId, repostOfId FROM publications WHERE repostOfId IN ("+placeholders(len(args))+")",
+		args...,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var publications []model.Publication
+	var reposts []model.Publication
 	for rows.Next() {
-		var publication model.Publication
+		var repost model.Publication
+		if err = rows.Scan(&repost.ID, &repost.AuthorID, &repost.RepostOfID); err != nil {
+			return nil, err
+		}
 
-		if err = rows.Scan(
-			&publication.ID,
-			&publication.Title,
-			&publication.Content,
-			&publication.AuthorID,
-			&publication.Likes,
-			&publication.CreatedAt,
-			&publication.AuthorNick,
-		); err != nil {
+		reposts = append(reposts, repost)
+	}
+
+	return reposts, rows.Err()
+}
+
+// scanPublications lê as publicações selecionadas com publicationColumns e carrega os seus anexos, as
+// suas hashtags e as suas menções
+func (repo Publications) scanPublications(rows *sql.Rows) ([]model.Publication, error) {
+	publications, err := scanPublicationRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.loadRelations(publications)
+}
+
+// scanPage lê uma página de publicações selecionadas com publicationColumns, carrega os anexos, as
+// hashtags e as menções e retorna o cursor da próxima página
+func (repo Publications) scanPage(rows *sql.Rows, page pagination.Page) ([]model.Publication, string, error) {
+	publications, err := scanPublicationRows(rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keys := make([]pagination.Cursor, 0, len(publications))
+	for _, publication := range publications {
+		keys = append(keys, pagination.Cursor{CreatedAt: publication.CreatedAt, ID: publication.ID})
+	}
+
+	publications, next := pagination.Trim(publications, keys, page.Limit)
+
+	if publications, err = repo.loadRelations(publications); err != nil {
+		return nil, "", err
+	}
+
+	return publications, next, nil
+}
+
+func scanPublicationRows(rows *sql.Rows) ([]model.Publication, error) {
+	var publications []model.Publication
+	for rows.Next() {
+		publication, err := scanPublicationRow(rows)
+		if err != nil {
 			return nil, err
 		}
 
 		publications = append(publications, publication)
 	}
 
-	return publications, nil
+	return publications, rows.Err()
 }
 
-// Update atualiza uma publicação no banco de dados
-func (repo Publications) Update(publicationID uint64, publication model.Publication) error {
-	statement, err := repo.db.Prepare("UPDATE publications SET title = ?, content = ? WHERE id = ?")
-	if err != nil {
+// scanPublicationRow lê a linha atual, selecionada com publicationColumns seguidas das colunas
+// lidas em extra
+func scanPublicationRow(rows *sql.Rows, extra ...interface{}) (model.Publication, error) {
+	var (
+		publication model.Publication
+		publishAt   sql.NullTime
+		editedAt    sql.NullTime
+		expiresAt   sql.NullTime
+		position    int
+	)
+
+	err := rows.Scan(append([]interface{}{
+		&publication.ID,
+		&publication.Title,
+		&publication.Content,
+		&publication.AuthorID,
+		&publication.Reposts,
+		&publication.Quotes,
+		&publication.RepostOfID,
+		&publication.QuotedID,
+		&publication.Status,
+		&publication.Visibility,
+		&publishAt,
+		&editedAt,
+		&publication.Pinned,
+		&publication.ThreadID,
+		&position,
+		&expiresAt,
+		&publication.CreatedAt,
+		&publication.AuthorNick,
+	}, extra...)...)
+
+	if publishAt.Valid {
+		publication.PublishAt = &publishAt.Time
+	}
+
+	if editedAt.Valid {
+		publication.EditedAt = &editedAt.Time
+	}
+
+	if expiresAt.Valid {
+		publication.ExpiresAt = &expiresAt.Time
+	}
+
+	// A posição da primeira parte é preenchida por loadThreads, que sabe se ela tem outras partes
+	if publication.ThreadID != 0 {
+		publication.ThreadPosition = position
+	}
+
+	publication.ContentHTML = markdown.ToHTML(publication.Content)
+
+	return publication, err
+}
+
+// loadRelations carrega os dados das publicações que ficam em outras tabelas e as publicações
+// originais de reposts e citações. Reposts cuja original não pode ser vista são descartados
+func (repo Publications) loadRelations(publications []model.Publication) ([]model.Publication, error) {
+	if err := repo.loadDetails(publications); err != nil {
+		return nil, err
+	}
+
+	return repo.loadOriginals(publications)
+}
+
+// loadDetails carrega os anexos, as hashtags, as reações, as enquetes, as menções e as threads das
+// publicações e, quando há um usuário consultando, se ele as salvou
+func (repo Publications) loadDetails(publications []model.Publication) error {
+	if err := repo.loadAttachments(publications); err != nil {
 		return err
 	}
-	defer statement.Close()
 
-	if _, err = statement.Exec(publication.Title, publication.Content, publicationID); err != nil {
+	if err := repo.loadTags(publications); err != nil {
 		return err
 	}
 
-	return nil
-}
+	if err := repo.loadReactions(publications); err != nil {
+		return err
+	}
 
-// Delete exclui uma publicação do banco de dados
-func (repo Publications) Delete(publicationID uint64) error {
-	statement, err := repo.db.Prepare("DELETE FROM publications WHERE id = ?")
-	if err != nil {
+	if err := repo.loadPolls(publications); err != nil {
 		return err
 	}
-	defer statement.Close()
 
-	if _, err := statement.Exec(publicationID); err != nil {
+	if err := repo.loadMentions(publications); err != nil {
 		return err
 	}
 
-	return nil
+	if err := repo.loadThreads(publications); err != nil {
+		return err
+	}
+
+	return repo.loadBookmarks(publications)
 }
 
-// GetAllPublicationsOfUser retorna todas as publicações de um usuário
-func (repo Publications) GetAllPublicationsOfUser(authorId uint64) ([]model.Publication, error) {
+// loadOriginals busca, em uma única consulta, as publicações repostadas e citadas. Quando há um
+// usuário consultando, as originais de usuários com bloqueio, de contas privadas que ele não segue e
+// as que a visibilidade não permite que ele veja são tratadas como indisponíveis
+func (repo Publications) loadOriginals(publications []model.Publication) ([]model.Publication, error) {
+	var args []interface{}
+	for _, publication := range publications {
+		if publication.RepostOfID != 0 {
+			args = append(args, publication.RepostOfID)
+		}
+
+		if publication.QuotedID != 0 {
+			args = append(args, publication.QuotedID)
+		}
+	}
+
+	if len(args) == 0 {
+		return publications, nil
+	}
+
+	query := `SELECT ` + publicationColumns + ` FROM publications AS p INNER JOIN users AS u ON p.authorId = u.id
+		WHERE p.id IN (` + placeholders(len(args)) + `) AND ` + publishedClause + ` AND ` + notExpiredClause
+
+	if repo.viewerID != 0 {
+		query += " AND " + notBlockedClause("p.authorId") + " AND " + canViewClause("p.authorId") +
+			" AND " + visibleClause("p")
+		args = append(args, repo.viewerID, repo.viewerID, repo.viewerID, repo.viewerID, repo.viewerID, repo.viewerID, repo.viewerID)
+	}
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	originals, err := scanPublicationRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = repo.loadDetails(originals); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint64]*model.Publication, len(originals))
+	for i := range originals {
+		byID[originals[i].ID] = &originals[i]
+	}
+
+	loaded := publications[:0]
+	for _, publication := range publications {
+		if publication.RepostOfID != 0 {
+			if publication.RepostOf = byID[publication.RepostOfID]; publication.RepostOf == nil {
+				continue
+			}
+		}
+
+		if publication.QuotedID != 0 {
+			publication.Quoted = byID[publication.QuotedID]
+			publication.QuoteUnavailable = publication.Quoted == nil
+		}
+
+		loaded = append(loaded, publication)
+	}
+
+	return loaded, nil
+}
+
+// loadAttachments busca, em uma única consulta, os anexos de todas as publicações informadas
+func (repo Publications) loadAttachments(publications []model.Publication) error {
+	if len(publications) == 0 {
+		return nil
+	}
+
+	indexes := make(map[uint64]int, len(publications))
+	args := make([]interface{}, 0, len(publications))
+	for i, publication := range publications {
+		indexes[publication.ID] = i
+		args = append(args, publication.ID)
+	}
+
 	rows, err := repo.db.Query(
-		`SELECT p.*, u.nick FROM publications AS p
-		INNER JOIN users AS u ON p.authorId = u.id
-		WHERE p.authorId = ?`,
-		authorId,
+		`SELECT pm.publicationId, pm.altText, m.id, m.storageKey, m.contentType, m.width, m.height
+		FROM publication_media AS pm INNER JOIN media AS m ON pm.mediaId = m.id
+		WHERE pm.publicationId IN (`+placeholders(len(args))+`) ORDER BY pm.publicationId, pm.position`,
+		args...,
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var publications []model.Publication
 	for rows.Next() {
-		var publication model.Publication
+		var (
+			publicationID uint64
+			attachment    model.Attachment
+		)
 
 		if err = rows.Scan(
-			&publication.ID,
-			&publication.Title,
-			&publication.Content,
-			&publication.AuthorID,
-			&publication.Likes,
-			&publication.CreatedAt,
-			&publication.AuthorNick,
+			&publicationID,
+			&attachment.AltText,
+			&attachment.Media.ID,
+			&attachment.Media.Key,
+			&attachment.Media.ContentType,
+			&attachment.Media.Width,
+			&attachment.Media.Height,
 		); err != nil {
-			return nil, err
+			return err
 		}
 
-		publications = append(publications, publication)
+		attachment.MediaID = attachment.Media.ID
+		media.FillURLs(&attachment.Media)
+
+		i := indexes[publicationID]
+		publications[i].Attachments = append(publications[i].Attachments, attachment)
 	}
 
-	return publications, nil
+	return rows.Err()
 }
 
-// Like adiciona em um o número de curtidas no banco de dados
-func (repo Publications) Like(publicationId uint64) error {
-	statement, err := repo.db.Prepare("UPDATE publications SET likes = likes + 1 WHERE id = ?")
+// loadTags busca, em uma única consulta, as hashtags de todas as publicações informadas
+func (repo Publications) loadTags(publications []model.Publication) error {
+	if len(publications) == 0 {
+		return nil
+	}
+
+	indexes := make(map[uint64]int, len(publications))
+	args := make([]interface{}, 0, len(publications))
+	for i, publication := range publications {
+		indexes[publication.ID] = i
+		args = append(args, publication.ID)
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT pt.publicationId, t.name FROM publication_tags AS pt INNER JOIN tags AS t ON pt.tagId = t.id
+		WHERE pt.publicationId IN (`+placeholders(len(args))+`) ORDER BY pt.publicationId, t.name`,
+		args...,
+	)
 	if err != nil {
 		return err
 	}
-	defer statement.Close()
+	defer rows.Close()
 
-	if _, err := statement.Exec(publicationId); err != nil {
-		return err
+	for rows.Next() {
+		var (
+			publicationID uint64
+			tag           string
+		)
+
+		if err = rows.Scan(&publicationID, &tag); err != nil {
+			return err
+		}
+
+		i := indexes[publicationID]
+		publications[i].Tags = append(publications[i].Tags, tag)
 	}
 
-	return nil
+	return rows.Err()
 }
 
-// Dislike subtrai em um o número de curtidas no banco de dados
-func (repo Publications) Dislike(publicationId uint64) error {
-	statement, err := repo.db.Prepare(
-		`UPDATE publications SET likes =
-		CASE WHEN likes > 0 THEN likes - 1
-		ELSE likes END
-		WHERE id = ?`,
+// loadMentions busca, em uma única consulta, as menções de todas as publicações informadas, com o
+// nick atual de cada usuário mencionado
+func (repo Publications) loadMentions(publications []model.Publication) error {
+	if len(publications) == 0 {
+		return nil
+	}
+
+	indexes := make(map[uint64]int, len(publications))
+	args := make([]interface{}, 0, len(publications))
+	for i, publication := range publications {
+		indexes[publication.ID] = i
+		args = append(args, publication.ID)
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT pm.publicationId, pm.userId, u.nick, pm.startOffset, pm.endOffset
+		FROM publication_mentions AS pm INNER JOIN users AS u ON pm.userId = u.id
+		WHERE pm.publicationId IN (`+placeholders(len(args))+`) ORDER BY pm.publicationId, pm.startOffset`,
+		args...,
 	)
 	if err != nil {
 		return err
 	}
-	defer statement.Close()
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			publicationID uint64
+			mention       model.Mention
+		)
+
+		if err = rows.Scan(&publicationID, &mention.UserID, &mention.Nick, &mention.Start, &mention.End); err != nil {
+			return err
+		}
+
+		i := indexes[publicationID]
+		publications[i].Mentions = append(publications[i].Mentions, mention)
+	}
+
+	return rows.Err()
+}
+
+func insertAttachments(tx *sql.Tx, publicationID uint64, attachments []model.Attachment) error {
+	for position, attachment := range attachments {
+		if _, err := tx.Exec(
+			"INSERT INTO publication_media (publicationId, mediaId, position, altText) VALUES (?, ?, ?, ?)",
+			publicationID, attachment.MediaID, position, attachment.AltText,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveTags associa as hashtags à publicação, criando as que ainda não existem e desfazendo as
+// associações que deixaram de existir. As associações mantidas preservam a data original, usada no
+// cálculo das hashtags em alta
+func saveTags(tx *sql.Tx, publicationID uint64, tags []string) error {
+	names := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag)
+	}
+
+	if len(tags) == 0 {
+		_, err := tx.Exec("DELETE FROM publication_tags WHERE publicationId = ?", publicationID)
+		return err
+	}
+
+	if _, err := tx.Exec(
+		`DELETE FROM publication_tags WHERE publicationId = ?
+		AND tagId NOT IN (SELECT id FROM tags WHERE name IN (`+placeholders(len(tags))+`))`,
+		append([]interface{}{publicationID}, names...)...,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"INSERT ignore INTO tags (name) VALUES "+strings.TrimSuffix(strings.Repeat("(?), ", len(tags)), ", "),
+		names...,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(
+		`INSERT ignore INTO publication_tags (publicationId, tagId)
+		SELECT ?, id FROM tags WHERE name IN (`+placeholders(len(tags))+`)`,
+		append([]interface{}{publicationID}, names...)...,
+	)
+	return err
+}
 
-	if _, err := statement.Exec(publicationId); err != nil {
+// saveMentions substitui as menções da publicação, ignorando as que não foram resolvidas para um
+// usuário
+func saveMentions(tx *sql.Tx, publicationID uint64, mentions []model.Mention) error {
+	if _, err := tx.Exec("DELETE FROM publication_mentions WHERE publicationId = ?", publicationID); err != nil {
 		return err
 	}
 
+	for _, mention := range mentions {
+		if mention.UserID == 0 {
+			continue
+		}
+
+		if _, err := tx.Exec(
+			"INSERT INTO publication_mentions (publicationId, userId, startOffset, endOffset) VALUES (?, ?, ?, ?)",
+			publicationID, mention.UserID, mention.Start, mention.End,
+		); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// onPublished atualiza os dados que dependem da publicação de um rascunho ou de uma publicação
+// agendada: o contador de citações da publicação citada e a data de uso das hashtags, considerada nos
+// assuntos do momento
+func onPublished(tx *sql.Tx, publicationID uint64) error {
+	if _, err := tx.Exec(
+		`UPDATE publications AS o INNER JOIN publications AS p ON p.quotedId = o.id
+		SET o.quotes = o.quotes + 1 WHERE p.id = ?`,
+		publicationID,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(
+		`UPDATE publication_tags AS pt INNER JOIN publications AS p ON pt.publicationId = p.id
+		SET pt.createdAt = p.createdAt WHERE p.id = ?`,
+		publicationID,
+	)
+	return err
+}
+
+// statusOrPublished retorna a situação informada, considerando publicada quando ela está vazia
+func statusOrPublished(status string) string {
+	if status == "" {
+		return model.StatusPublished
+	}
+
+	return status
+}
+
+// visibilityOrPublic retorna a visibilidade informada, considerando pública quando ela está vazia
+func visibilityOrPublic(visibility string) string {
+	if visibility == "" {
+		return model.VisibilityPublic
+	}
+
+	return visibility
+}
+
+// nullableID converte o id zero em NULL para colunas de referência opcionais
+func nullableID(id uint64) interface{} {
+	if id == 0 {
+		return nil
+	}
+
+	return id
+}
+
+// placeholders retorna n marcadores separados por vírgula para uso em cláusulas IN
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"api.devbook/src/model"
+)
+
+func TestRepostConcurrently(t *testing.T) {
+	db := openTestDB(t)
+
+	const attempts = 10
+
+	authorID := createTestUser(t, db)
+	reposterID := createTestUser(t, db)
+	originalID := createTestPublication(t, db, authorID, model.Publication{})
+
+	repo := NewRepositoryOfPublications(db)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := repo.Create(model.Publication{AuthorID: reposterID, RepostOfID: originalID})
+			switch {
+			case err == nil:
+				mu.Lock()
+				created++
+				mu.Unlock()
+			case !errors.Is(err, ErrAlreadyReposted):
+				t.Errorf("Create: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Errorf("%d reposts criados, want 1", created)
+	}
+
+	original, err := repo.GetById(originalID)
+	if err != nil {
+		t.Fatalf("GetById: %v", err)
+	}
+
+	if original.Reposts != 1 {
+		t.Errorf("Reposts = %d, want 1", original.Reposts)
+	}
+}
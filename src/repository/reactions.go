@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+
+	"api.devbook/src/model"
+)
+
+// React registra a reação do usuário na publicação, substituindo a reação anterior dele. O total de
+// reações da publicação só é incrementado quando o usuário ainda não havia reagido. Retorna
+// sql.ErrNoRows quando a publicação não existe
+func (repo Publications) React(publicationID, userID uint64, reaction string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// O MySQL informa uma linha afetada na inserção, duas na troca de reação e nenhuma quando a
+	// reação é a mesma
+	result, err := tx.Exec(
+		`INSERT INTO publication_reactions (publicationId, userId, type) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE type = VALUES(type)`,
+		publicationID, userID, reaction,
+	)
+	if err != nil {
+		if isMySQLError(err, errNoReferencedRow) {
+			return sql.ErrNoRows
+		}
+
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 1 {
+		if _, err = tx.Exec("UPDATE publications SET reactions = reactions + 1 WHERE id = ?", publicationID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Unreact remove a reação do usuário na publicação, decrementando o total de reações apenas se ele
+// havia reagido. Retorna sql.ErrNoRows quando a publicação não existe
+func (repo Publications) Unreact(publicationID, userID uint64) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"DELETE FROM publication_reactions WHERE publicationId = ? AND userId = ?",
+		publicationID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		var id uint64
+		return tx.QueryRow("SELECT id FROM publications WHERE id = ?", publicationID).Scan(&id)
+	}
+
+	if _, err = tx.Exec(
+		"UPDATE publications SET reactions = reactions - 1 WHERE id = ? AND reactions > 0", publicationID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// loadReactions carrega a quantidade de reações de cada tipo das publicações, contadas a partir das
+// reações gravadas, as curtidas, que são as reações like, e a reação do usuário que está consultando
+func (repo Publications) loadReactions(publications []model.Publication) error {
+	if len(publications) == 0 {
+		return nil
+	}
+
+	indexes := make(map[uint64][]int, len(publications))
+	args := make([]interface{}, 0, len(publications)+1)
+	args = append(args, repo.viewerID)
+	for i, publication := range publications {
+		publications[i].Reactions = map[string]uint64{}
+		indexes[publication.ID] = append(indexes[publication.ID], i)
+		args = append(args, publication.ID)
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT publicationId, type, COUNT(*), MAX(userId = ?) FROM publication_reactions
+		WHERE publicationId IN (`+placeholders(len(publications))+`)
+		GROUP BY publicationId, type`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			publicationID uint64
+			reaction      string
+			count         uint64
+			mine          bool
+		)
+
+		if err = rows.Scan(&publicationID, &reaction, &count, &mine); err != nil {
+			return err
+		}
+
+		for _, i := range indexes[publicationID] {
+			publications[i].Reactions[reaction] = count
+			if reaction == model.ReactionLike {
+				publications[i].Likes = count
+			}
+
+			if mine {
+				publications[i].MyReaction = reaction
+			}
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"api.devbook/src/model"
+)
+
+func TestReact(t *testing.T) {
+	db := openTestDB(t)
+
+	tests := []struct {
+		name      string
+		reactions []string
+		want      string
+	}{
+		{name: "primeira reação", reactions: []string{model.ReactionLike}, want: model.ReactionLike},
+		{name: "mesma reação", reactions: []string{model.ReactionLike, model.ReactionLike}, want: model.ReactionLike},
+		{name: "troca de reação", reactions: []string{model.ReactionLike, model.ReactionLove}, want: model.ReactionLove},
+		{
+			name:      "troca e volta",
+			reactions: []string{model.ReactionLike, model.ReactionSad, model.ReactionLike},
+			want:      model.ReactionLike,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorID := createTestUser(t, db)
+			userID := createTestUser(t, db)
+			publicationID := createTestPublication(t, db, authorID, model.Publication{})
+
+			repo := NewRepositoryOfPublications(db)
+			for _, reaction := range tt.reactions {
+				if err := repo.React(publicationID, userID, reaction); err != nil {
+					t.Fatalf("React(%s): %v", reaction, err)
+				}
+			}
+
+			var stored string
+			if err := db.QueryRow(
+				"SELECT type FROM publication_reactions WHERE publicationId = ? AND userId = ?",
+				publicationID, userID,
+			).Scan(&stored); err != nil {
+				t.Fatalf("ler a reação: %v", err)
+			}
+
+			if stored != tt.want {
+				t.Errorf("reação gravada = %q, want %q", stored, tt.want)
+			}
+
+			var total uint64
+			if err := db.QueryRow("SELECT reactions FROM publications WHERE id = ?", publicationID).Scan(&total); err != nil {
+				t.Fatalf("ler o total de reações: %v", err)
+			}
+
+			if total != 1 {
+				t.Errorf("total de reações = %d, want 1", total)
+			}
+
+			publication, err := repo.WithViewer(userID).GetById(publicationID)
+			if err != nil {
+				t.Fatalf("GetById: %v", err)
+			}
+
+			if want := map[string]uint64{tt.want: 1}; !reflect.DeepEqual(publication.Reactions, want) {
+				t.Errorf("Reactions = %v, want %v", publication.Reactions, want)
+			}
+
+			if publication.MyReaction != tt.want {
+				t.Errorf("MyReaction = %q, want %q", publication.MyReaction, tt.want)
+			}
+		})
+	}
+}
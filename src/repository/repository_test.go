@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"api.devbook/src/model"
+	_ "github.com/go-sql-driver/mysql" // Driver
+)
+
+// testDatabaseEnv é a variável com a string de conexão de um banco MySQL com o esquema de sql/sql.sql,
+// usado pelos testes que dependem das consultas. Sem ela, esses testes são ignorados
+const testDatabaseEnv = "DEVBOOK_TEST_DATABASE"
+
+var testUsers uint64
+
+// openTestDB abre o banco de dados de testes, que é fechado ao final do teste
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skip(testDatabaseEnv + " não definida")
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+
+	if err = db.Ping(); err != nil {
+		db.Close()
+		t.Fatalf("db.Ping: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// createTestUser cria um usuário com nick e e-mail únicos. Ao final do teste o usuário é excluído,
+// junto com tudo o que depende dele
+func createTestUser(t *testing.T, db *sql.DB) uint64 {
+	t.Helper()
+
+	suffix := fmt.Sprintf("%d%d", time.Now().UnixNano()%1e9, atomic.AddUint64(&testUsers, 1))
+
+	id, err := NewRepositoryOfUsers(db).Create(model.User{
+		Name:     "Teste",
+		Nick:     "t" + suffix,
+		Email:    "t" + suffix + "@example.com",
+		Password: "-",
+	})
+	if err != nil || id == 0 {
+		t.Fatalf("criar usuário: %d, %v", id, err)
+	}
+
+	t.Cleanup(func() {
+		if err := NewRepositoryOfUsers(db).Delete(id); err != nil {
+			t.Errorf("excluir usuário %d: %v", id, err)
+		}
+	})
+
+	return id
+}
+
+// createTestPublication grava a publicação do autor e retorna o id dela
+func createTestPublication(t *testing.T, db *sql.DB, authorID uint64, publication model.Publication) uint64 {
+	t.Helper()
+
+	publication.AuthorID = authorID
+	if publication.Title == "" {
+		publication.Title = "Teste"
+	}
+	if publication.Content == "" {
+		publication.Content = "Conteúdo de teste"
+	}
+
+	id, err := NewRepositoryOfPublications(db).Create(publication)
+	if err != nil {
+		t.Fatalf("criar publicação: %v", err)
+	}
+
+	return id
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// GetRevisions retorna uma página das versões anteriores da publicação, da edição mais recente para a
+// mais antiga
+func (repo Publications) GetRevisions(publicationID uint64, page pagination.Page) ([]model.Revision, string, error) {
+	after, order, cursorArgs := pageClauses("r.createdAt", "r.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT r.id, r.publicationId, r.title, r.content, r.createdAt FROM publication_revisions AS r
+		WHERE r.publicationId = ?`+after+order,
+		append([]interface{}{publicationID}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var (
+		revisions []model.Revision
+		keys      []pagination.Cursor
+	)
+
+	for rows.Next() {
+		var revision model.Revision
+		if err = rows.Scan(
+			&revision.ID,
+			&revision.PublicationID,
+			&revision.Title,
+			&revision.Content,
+			&revision.CreatedAt,
+		); err != nil {
+			return nil, "", err
+		}
+
+		revisions = append(revisions, revision)
+		keys = append(keys, pagination.Cursor{CreatedAt: revision.CreatedAt, ID: revision.ID})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	revisions, next := pagination.Trim(revisions, keys, page.Limit)
+	return revisions, next, nil
+}
+
+// GetRevision retorna a versão anterior da publicação, ou sql.ErrNoRows caso ela não pertença à
+// publicação
+func (repo Publications) GetRevision(publicationID, revisionID uint64) (model.Revision, error) {
+	var revision model.Revision
+
+	err := repo.db.QueryRow(
+		"SELECT id, publicationId, title, content, createdAt FROM publication_revisions WHERE id = ? AND publicationId = ?",
+		revisionID, publicationID,
+	).Scan(&revision.ID, &revision.PublicationID, &revision.Title, &revision.Content, &revision.CreatedAt)
+
+	return revision, err
+}
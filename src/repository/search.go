@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"unicode/utf8"
+
+	"api.devbook/src/model"
+	"api.devbook/src/search"
+)
+
+// GetAllForSearch retorna o título, o conteúdo, o autor e a data de todas as publicações publicadas e
+// não expiradas, exceto os reposts, que não têm conteúdo próprio, usados para carregar o índice de
+// busca em memória
+func (repo Publications) GetAllForSearch() ([]model.Publication, error) {
+	rows, err := repo.db.Query(
+		`SELECT p.id, p.title, p.content, p.authorId, p.createdAt FROM publications AS p
+		WHERE p.repostOfId IS NULL AND ` + publishedClause + ` AND ` + notExpiredClause,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var publications []model.Publication
+	for rows.Next() {
+		var publication model.Publication
+		if err = rows.Scan(
+			&publication.ID,
+			&publication.Title,
+			&publication.Content,
+			&publication.AuthorID,
+			&publication.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		publications = append(publications, publication)
+	}
+
+	return publications, rows.Err()
+}
+
+// GetSearchCandidates retorna até limit usuários que podem corresponder ao termo: os que contêm o
+// termo no nick ou no nome e, para tolerar erros de digitação, os que têm o nick ou uma palavra do
+// nome começando pela mesma letra. Os mais prováveis são buscados primeiro e a ordenação final é
+// feita por search.RankUsers. Usuários com bloqueio com quem está buscando são omitidos
+func (repo Users) GetSearchCandidates(term string, viewerID uint64, limit int) ([]search.UserCandidate, error) {
+	escaped := escapeLike(term)
+	contains := "%" + escaped + "%"
+	prefix := escaped + "%"
+
+	first, _ := utf8.DecodeRuneInString(term)
+	firstLetter := escapeLike(string(first)) + "%"
+
+	rows, err := repo.db.Query(
+		`SELECT `+userColumns+`, EXISTS (
+			SELECT 1 FROM followers AS f WHERE f.userId = u.id AND f.followerId = ?
+		) FROM users AS u
+		WHERE (u.nick LIKE ? OR u.name LIKE ? OR u.nick LIKE ? OR u.name LIKE ? OR u.name LIKE ?)
+		AND `+notBlockedClause("u.id")+`
+		ORDER BY u.nick = ? DESC, u.nick LIKE ? DESC, (u.nick LIKE ? OR u.name LIKE ?) DESC, u.id
+		LIMIT ?`,
+		viewerID,
+		contains, contains, firstLetter, firstLetter, "% "+firstLetter,
+		viewerID, viewerID,
+		term, prefix, contains, contains,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanUserCandidates(rows)
+}
+
+// GetSuggestionCandidates retorna até limit usuários cujo nick, nome ou alguma palavra do nome
+// começam pelo prefixo, priorizando os seguidos por quem está buscando. Usuários com bloqueio com
+// quem está buscando são omitidos
+func (repo Users) GetSuggestionCandidates(prefix string, viewerID uint64, limit int) ([]search.UserCandidate, error) {
+	pattern := escapeLike(prefix) + "%"
+
+	rows, err := repo.db.Query(
+		`SELECT `+userColumns+`, EXISTS (
+			SELECT 1 FROM followers AS f WHERE f.userId = u.id AND f.followerId = ?
+		) AS followed FROM users AS u
+		WHERE (u.nick LIKE ? OR u.name LIKE ? OR u.name LIKE ?) AND `+notBlockedClause("u.id")+`
+		ORDER BY followed DESC, CHAR_LENGTH(u.nick), u.id LIMIT ?`,
+		viewerID, pattern, pattern, "% "+pattern, viewerID, viewerID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanUserCandidates(rows)
+}
+
+func scanUserCandidates(rows *sql.Rows) ([]search.UserCandidate, error) {
+	var candidates []search.UserCandidate
+	for rows.Next() {
+		var candidate search.UserCandidate
+
+		if err := rows.Scan(
+			&candidate.User.ID,
+			&candidate.User.Name,
+			&candidate.User.Nick,
+			&candidate.User.Email,
+			&candidate.User.Private,
+			&candidate.User.Avatar,
+			&candidate.User.CreatedAt,
+			&candidate.Followed,
+		); err != nil {
+			return nil, err
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// escapeLike escapa os caracteres especiais do LIKE para que o termo seja buscado literalmente
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
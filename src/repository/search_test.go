@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"api.devbook/src/model"
+	"api.devbook/src/search"
+)
+
+func TestMySQLSearchFilters(t *testing.T) {
+	db := openTestDB(t)
+
+	tests := []struct {
+		name       string
+		visibility string
+		// setup prepara a relação entre o autor e quem está buscando
+		setup func(db *sql.DB, authorID, viewerID uint64) error
+		want  bool
+	}{
+		{name: "pública", want: true},
+		{
+			name: "autor bloqueado",
+			setup: func(db *sql.DB, authorID, viewerID uint64) error {
+				_, _, err := NewRepositoryOfUsers(db).Block(viewerID, authorID)
+				return err
+			},
+		},
+		{
+			name: "bloqueado pelo autor",
+			setup: func(db *sql.DB, authorID, viewerID uint64) error {
+				_, _, err := NewRepositoryOfUsers(db).Block(authorID, viewerID)
+				return err
+			},
+		},
+		{
+			name: "autor silenciado",
+			setup: func(db *sql.DB, authorID, viewerID uint64) error {
+				return NewRepositoryOfUsers(db).Mute(viewerID, authorID)
+			},
+		},
+		{
+			name: "conta privada não seguida",
+			setup: func(db *sql.DB, authorID, viewerID uint64) error {
+				_, err := db.Exec("UPDATE users SET private = true WHERE id = ?", authorID)
+				return err
+			},
+		},
+		{
+			name: "conta privada seguida",
+			setup: func(db *sql.DB, authorID, viewerID uint64) error {
+				if _, err := db.Exec("UPDATE users SET private = true WHERE id = ?", authorID); err != nil {
+					return err
+				}
+
+				return NewRepositoryOfUsers(db).Follow(viewerID, authorID)
+			},
+			want: true,
+		},
+		{name: "apenas para seguidores", visibility: model.VisibilityFollowers},
+		{name: "apenas para os mencionados", visibility: model.VisibilityMentioned},
+	}
+
+	searcher := search.NewMySQL(db, ViewableClause)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorID := createTestUser(t, db)
+			viewerID := createTestUser(t, db)
+
+			if tt.setup != nil {
+				if err := tt.setup(db, authorID, viewerID); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+			}
+
+			word := fmt.Sprintf("busca%d", time.Now().UnixNano())
+			publicationID := createTestPublication(t, db, authorID, model.Publication{
+				Content:    "publicação com " + word,
+				Visibility: tt.visibility,
+			})
+
+			hits, err := searcher.Search(search.Query{Terms: []string{word}, ViewerID: viewerID}, 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+
+			if got := len(hits) == 1 && hits[0].PublicationID == publicationID; got != tt.want {
+				t.Errorf("encontrada = %v, want %v (%v)", got, tt.want, hits)
+			}
+		})
+	}
+}
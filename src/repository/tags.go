@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/ranking"
+)
+
+// Tags representa um repositório de hashtags
+type Tags struct {
+	db *sql.DB
+}
+
+// NewRepositoryOfTags cria um repositório de hashtags
+func NewRepositoryOfTags(db *sql.DB) *Tags {
+	return &Tags{db}
+}
+
+// GetIDsByNames retorna os ids das hashtags informadas que já existem
+func (repo Tags) GetIDsByNames(names []string) ([]uint64, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		args = append(args, name)
+	}
+
+	rows, err := repo.db.Query("SELECT id FROM tags WHERE name IN ("+placeholders(len(names))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIDs(rows)
+}
+
+// GetOrCreate retorna o id da hashtag, criando-a caso ainda não exista
+func (repo Tags) GetOrCreate(name string) (uint64, error) {
+	if _, err := repo.db.Exec("INSERT ignore INTO tags (name) VALUES (?)", name); err != nil {
+		return 0, err
+	}
+
+	var id uint64
+	if err := repo.db.QueryRow("SELECT id FROM tags WHERE name = ?", name).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Follow registra que o usuário passou a seguir a hashtag
+func (repo Tags) Follow(userID, tagID uint64) error {
+	_, err := repo.db.Exec("INSERT ignore INTO tag_followers (tagId, userId) VALUES (?, ?)", tagID, userID)
+	return err
+}
+
+// Unfollow registra que o usuário deixou de seguir a hashtag
+func (repo Tags) Unfollow(userID, tagID uint64) error {
+	_, err := repo.db.Exec("DELETE FROM tag_followers WHERE tagId = ? AND userId = ?", tagID, userID)
+	return err
+}
+
+// GetAllFollowed retorna uma página das hashtags seguidas pelo usuário, das seguidas mais
+// recentemente para as mais antigas
+func (repo Tags) GetAllFollowed(userID uint64, page pagination.Page) ([]model.Tag, string, error) {
+	after, order, cursorArgs := pageClauses("tf.createdAt", "t.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT t.id, t.name, t.createdAt, tf.createdAt FROM tags AS t
+		INNER JOIN tag_followers AS tf ON tf.tagId = t.id
+		WHERE tf.userId = ?`+after+order,
+		append([]interface{}{userID}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var (
+		tags []model.Tag
+		keys []pagination.Cursor
+	)
+
+	for rows.Next() {
+		var (
+			tag model.Tag
+			key time.Time
+		)
+
+		if err = rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &key); err != nil {
+			return nil, "", err
+		}
+
+		tags = append(tags, tag)
+		keys = append(keys, pagination.Cursor{CreatedAt: key, ID: tag.ID})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	tags, next := pagination.Trim(tags, keys, page.Limit)
+	return tags, next, nil
+}
+
+// GetFollowerIDs retorna os ids dos seguidores das hashtags informadas, ignorando as hashtags com mais
+// seguidores do que o limite, cujas publicações são buscadas na leitura das linhas do tempo
+func (repo Tags) GetFollowerIDs(tagIDs []uint64, maxFollowers int) ([]uint64, error) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(tagIDs)+1)
+	for _, tagID := range tagIDs {
+		args = append(args, tagID)
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT DISTINCT tf.userId FROM tag_followers AS tf
+		WHERE tf.tagId IN (`+placeholders(len(tagIDs))+`)
+		AND (SELECT COUNT(*) FROM tag_followers AS c WHERE c.tagId = tf.tagId) <= ?`,
+		append(args, maxFollowers)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIDs(rows)
+}
+
+// GetFollowedPopular retorna os ids das hashtags seguidas pelo usuário que possuem mais seguidores
+// do que o limite informado
+func (repo Tags) GetFollowedPopular(userID uint64, threshold int) ([]uint64, error) {
+	rows, err := repo.db.Query(
+		`SELECT tf.tagId FROM tag_followers AS tf WHERE tf.userId = ?
+		AND (SELECT COUNT(*) FROM tag_followers AS c WHERE c.tagId = tf.tagId) > ?`,
+		userID, threshold,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIDs(rows)
+}
+
+// GetUsage retorna, para cada hashtag usada desde a data informada, a quantidade de autores distintos
+// que a usaram em cada hora. Publicações de contas privadas e as que não são públicas não são
+// consideradas
+func (repo Tags) GetUsage(since time.Time) ([]ranking.TagUsage, error) {
+	rows, err := repo.db.Query(
+		`SELECT t.name, FLOOR(UNIX_TIMESTAMP(pt.createdAt) / 3600) AS hour, COUNT(DISTINCT p.authorId)
+		FROM publication_tags AS pt
+		INNER JOIN tags AS t ON pt.tagId = t.id
+		INNER JOIN publications AS p ON pt.publicationId = p.id
+		INNER JOIN users AS u ON p.authorId = u.id
+		WHERE pt.createdAt >= ? AND u.private = false AND `+publishedClause+` AND `+notExpiredClause+`
+		AND p.visibility = '`+model.VisibilityPublic+`'
+		GROUP BY t.name, hour`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []ranking.TagUsage
+	for rows.Next() {
+		var (
+			usage ranking.TagUsage
+			hour  int64
+		)
+
+		if err = rows.Scan(&usage.Tag, &hour, &usage.Authors); err != nil {
+			return nil, err
+		}
+
+		// O uso é considerado no meio da hora em que ocorreu
+		usage.At = time.Unix(hour*3600+1800, 0)
+		usages = append(usages, usage)
+	}
+
+	return usages, rows.Err()
+}
+
+// GetAllPublicationsOfTag retorna uma página das publicações com a hashtag, omitindo as de usuários
+// que possuem bloqueio com quem está consultando ou que ele silenciou, as de contas privadas que ele
+// não segue e as que a visibilidade não permite que ele veja
+func (repo Publications) GetAllPublicationsOfTag(tagID, viewerID uint64, page pagination.Page) ([]model.Publication, string, error) {
+	after, order, cursorArgs := pageClauses("p.createdAt", "p.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT `+publicationColumns+` FROM publications AS p
+		INNER JOIN users AS u ON p.authorId = u.id
+		INNER JOIN publication_tags AS pt ON pt.publicationId = p.id
+		WHERE pt.tagId = ? AND `+publishedClause+` AND `+notExpiredClause+`
+		AND `+notBlockedClause("p.authorId")+` AND `+notMutedClause("p.authorId")+`
+		AND `+canViewClause("p.authorId")+` AND `+visibleClause("p")+after+order,
+		append([]interface{}{tagID, viewerID, viewerID, viewerID, viewerID, viewerID, viewerID, viewerID, viewerID}, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	return repo.WithViewer(viewerID).scanPage(rows, page)
+}
+
+func scanIDs(rows *sql.Rows) ([]uint64, error) {
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
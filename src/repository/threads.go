@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"api.devbook/src/model"
+)
+
+// threadHeadClause restringe a consulta às publicações que não são partes seguintes de uma thread.
+// Nas linhas do tempo e no feed, uma thread aparece apenas pela primeira parte
+const threadHeadClause = "p.threadId IS NULL"
+
+// ErrNotThreadable é retornado ao continuar um repost, um rascunho ou uma publicação agendada
+var ErrNotThreadable = errors.New("Apenas publicações próprias já publicadas podem ser continuadas em uma thread")
+
+// ErrNotLastPart é retornado ao continuar uma thread a partir de uma parte que não é a última
+var ErrNotLastPart = errors.New("Apenas a última parte de uma thread pode ser continuada")
+
+// ErrThreadTooLong é retornado ao continuar uma thread que já atingiu o limite de partes
+var ErrThreadTooLong = fmt.Errorf("Uma thread deve ter no máximo %d partes", model.MaxThreadLength)
+
+// ContinueThread cria a publicação como a próxima parte da thread da publicação informada, que deve
+// ser a última parte da thread ou uma publicação que ainda não tem continuação, e retorna o id dela
+func (repo Publications) ContinueThread(publicationID uint64, part model.Publication) (uint64, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var (
+		threadID uint64
+		status   string
+		repostOf uint64
+		position int
+	)
+
+	if err = tx.QueryRow(
+		"SELECT COALESCE(threadId, id), status, COALESCE(repostOfId, 0), threadPosition FROM publications WHERE id = ?",
+		publicationID,
+	).Scan(&threadID, &status, &repostOf, &position); err != nil {
+		return 0, err
+	}
+
+	if status != model.StatusPublished || repostOf != 0 {
+		return 0, ErrNotThreadable
+	}
+
+	// A primeira parte é bloqueada para que continuações simultâneas não disputem a mesma posição
+	var locked uint64
+	if err = tx.QueryRow("SELECT id FROM publications WHERE id = ? FOR UPDATE", threadID).Scan(&locked); err != nil {
+		return 0, err
+	}
+
+	var last int
+	if err = tx.QueryRow(
+		"SELECT COALESCE(MAX(threadPosition), 1) FROM publications WHERE threadId = ?", threadID,
+	).Scan(&last); err != nil {
+		return 0, err
+	}
+
+	if position != last {
+		return 0, ErrNotLastPart
+	}
+
+	if last >= model.MaxThreadLength {
+		return 0, ErrThreadTooLong
+	}
+
+	part.ThreadID = threadID
+	part.ThreadPosition = last + 1
+
+	partID, err := insertPublication(tx, part)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return partID, nil
+}
+
+// GetThread retorna, em ordem, todas as partes publicadas da thread que começa pela publicação
+// informada. Quando há um usuário consultando, cada parte é filtrada pelos bloqueios, pela privacidade
+// da conta do autor e pela própria visibilidade, pois as partes restritas aos mencionados dependem das
+// menções de cada uma
+func (repo Publications) GetThread(threadID uint64) ([]model.Publication, error) {
+	query := `SELECT ` + publicationColumns + ` FROM publications AS p INNER JOIN users AS u ON p.authorId = u.id
+		WHERE (p.id = ? OR p.threadId = ?) AND ` + publishedClause + ` AND ` + notExpiredClause
+	args := []interface{}{threadID, threadID}
+
+	if repo.viewerID != 0 {
+		query += " AND " + notBlockedClause("p.authorId") + " AND " + canViewClause("p.authorId") +
+			" AND " + visibleClause("p")
+		args = append(args, repo.viewerID, repo.viewerID, repo.viewerID, repo.viewerID, repo.viewerID, repo.viewerID, repo.viewerID)
+	}
+
+	rows, err := repo.db.Query(query+" ORDER BY p.threadPosition", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return repo.scanPublications(rows)
+}
+
+// loadThreads busca, em uma única consulta, a quantidade de partes das threads das publicações
+// informadas e preenche a posição, o total e quantas partes vêm depois de cada uma
+func (repo Publications) loadThreads(publications []model.Publication) error {
+	if len(publications) == 0 {
+		return nil
+	}
+
+	indexes := make(map[uint64][]int, len(publications))
+	args := make([]interface{}, 0, len(publications))
+	for i, publication := range publications {
+		threadID := publication.ThreadID
+		if threadID == 0 {
+			threadID = publication.ID
+		}
+
+		if _, ok := indexes[threadID]; !ok {
+			args = append(args, threadID)
+		}
+		indexes[threadID] = append(indexes[threadID], i)
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT p.threadId, COUNT(*) FROM publications AS p
+		WHERE p.threadId IN (`+placeholders(len(args))+`) AND `+publishedClause+` AND `+notExpiredClause+`
+		GROUP BY p.threadId`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			threadID uint64
+			parts    int
+		)
+
+		if err = rows.Scan(&threadID, &parts); err != nil {
+			return err
+		}
+
+		for _, i := range indexes[threadID] {
+			publication := &publications[i]
+			if publication.ThreadID == 0 {
+				publication.ThreadPosition = 1
+			}
+
+			publication.ThreadLength = parts + 1
+			publication.ThreadRemaining = publication.ThreadLength - publication.ThreadPosition
+		}
+	}
+
+	return rows.Err()
+}
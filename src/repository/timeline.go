@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"database/sql"
+
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// GetTimelineEntries retorna as chaves das publicações mais recentes da linha do tempo do usuário: as
+// próprias publicações, as dos usuários que ele segue e as das hashtags que ele segue. Das threads,
+// apenas a primeira parte entra nas linhas do tempo
+func (repo Publications) GetTimelineEntries(id uint64, limit int) ([]pagination.Cursor, error) {
+	rows, err := repo.db.Query(
+		`SELECT p.createdAt, p.id FROM publications AS p
+		WHERE `+publishedClause+` AND `+notExpiredClause+` AND `+threadHeadClause+` AND (
+			p.authorId = ? OR p.authorId IN (SELECT f.userId FROM followers AS f WHERE f.followerId = ?)
+			OR p.id IN (
+				SELECT pt.publicationId FROM publication_tags AS pt
+				INNER JOIN tag_followers AS tf ON tf.tagId = pt.tagId WHERE tf.userId = ?
+			)
+		)
+		ORDER BY p.createdAt DESC, p.id DESC LIMIT ?`,
+		id, id, id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// GetEntriesOfAuthors retorna as chaves das publicações dos autores informados posteriores ao cursor
+// da página, buscando um item além do limite
+func (repo Publications) GetEntriesOfAuthors(authorIDs []uint64, page pagination.Page) ([]pagination.Cursor, error) {
+	if len(authorIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(authorIDs)+3)
+	for _, authorID := range authorIDs {
+		args = append(args, authorID)
+	}
+
+	after, order, cursorArgs := pageClauses("p.createdAt", "p.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT p.createdAt, p.id FROM publications AS p
+		WHERE p.authorId IN (`+placeholders(len(authorIDs))+`) AND `+publishedClause+`
+		AND `+notExpiredClause+` AND `+threadHeadClause+after+order,
+		append(args, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// GetEntriesOfTags retorna as chaves das publicações com as hashtags informadas posteriores ao cursor
+// da página, buscando um item além do limite
+func (repo Publications) GetEntriesOfTags(tagIDs []uint64, page pagination.Page) ([]pagination.Cursor, error) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(tagIDs)+3)
+	for _, tagID := range tagIDs {
+		args = append(args, tagID)
+	}
+
+	after, order, cursorArgs := pageClauses("p.createdAt", "p.id", page)
+
+	rows, err := repo.db.Query(
+		`SELECT DISTINCT p.createdAt, p.id FROM publications AS p
+		INNER JOIN publication_tags AS pt ON pt.publicationId = p.id
+		WHERE pt.tagId IN (`+placeholders(len(tagIDs))+`) AND `+publishedClause+`
+		AND `+notExpiredClause+` AND `+threadHeadClause+after+order,
+		append(args, cursorArgs...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// GetEntriesOnlyOfTag retorna as chaves das publicações com a hashtag que estão na linha do tempo do
+// usuário apenas por causa dela: as que não são dele, nem de quem ele segue, nem de outra hashtag
+// que ele segue
+func (repo Publications) GetEntriesOnlyOfTag(id, tagID uint64, limit int) ([]pagination.Cursor, error) {
+	rows, err := repo.db.Query(
+		`SELECT p.createdAt, p.id FROM publications AS p
+		INNER JOIN publication_tags AS pt ON pt.publicationId = p.id
+		WHERE pt.tagId = ? AND p.authorId <> ? AND `+publishedClause+` AND `+notExpiredClause+` AND `+threadHeadClause+`
+		AND p.authorId NOT IN (SELECT f.userId FROM followers AS f WHERE f.followerId = ?)
+		AND NOT EXISTS (
+			SELECT 1 FROM publication_tags AS o INNER JOIN tag_followers AS tf ON tf.tagId = o.tagId
+			WHERE o.publicationId = p.id AND o.tagId <> pt.tagId AND tf.userId = ?
+		)
+		ORDER BY p.createdAt DESC, p.id DESC LIMIT ?`,
+		tagID, id, id, id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// GetEntriesOnlyOfAuthor retorna as chaves das publicações do autor que não estariam na linha do tempo
+// do usuário sem segui-lo: as que não têm nenhuma hashtag que ele segue
+func (repo Publications) GetEntriesOnlyOfAuthor(id, authorID uint64, limit int) ([]pagination.Cursor, error) {
+	rows, err := repo.db.Query(
+		`SELECT p.createdAt, p.id FROM publications AS p
+		WHERE p.authorId = ? AND `+publishedClause+` AND `+notExpiredClause+` AND `+threadHeadClause+`
+		AND NOT EXISTS (
+			SELECT 1 FROM publication_tags AS pt INNER JOIN tag_followers AS tf ON tf.tagId = pt.tagId
+			WHERE pt.publicationId = p.id AND tf.userId = ?
+		)
+		ORDER BY p.createdAt DESC, p.id DESC LIMIT ?`,
+		authorID, id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// GetByIDs retorna as publicações informadas na mesma ordem dos ids, omitindo as que não existem ou
+// ainda não foram publicadas, as de usuários bloqueados ou silenciados pelo usuário que está
+// consultando, as de contas privadas que ele não segue e as que a visibilidade não permite que ele veja
+func (repo Publications) GetByIDs(viewerID uint64, publicationIDs []uint64) ([]model.Publication, error) {
+	if len(publicationIDs) == 0 {
+		return []model.Publication{}, nil
+	}
+
+	viewable, viewableArgs := ViewableClause(viewerID)
+
+	args := make([]interface{}, 0, len(publicationIDs)+len(viewableArgs))
+	for _, publicationID := range publicationIDs {
+		args = append(args, publicationID)
+	}
+	args = append(args, viewableArgs...)
+
+	rows, err := repo.db.Query(
+		`SELECT `+publicationColumns+` FROM publications AS p INNER JOIN users AS u ON p.authorId = u.id
+		WHERE p.id IN (`+placeholders(len(publicationIDs))+`) AND `+publishedClause+` AND `+notExpiredClause+`
+		AND `+viewable,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found, err := repo.WithViewer(viewerID).scanPublications(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint64]model.Publication, len(found))
+	for _, publication := range found {
+		byID[publication.ID] = publication
+	}
+
+	publications := make([]model.Publication, 0, len(found))
+	for _, publicationID := range publicationIDs {
+		if publication, ok := byID[publicationID]; ok {
+			publications = append(publications, publication)
+		}
+	}
+
+	return publications, nil
+}
+
+// GetFollowerIDs retorna os ids de todos os seguidores do usuário
+func (repo Users) GetFollowerIDs(id uint64) ([]uint64, error) {
+	rows, err := repo.db.Query("SELECT followerId FROM followers WHERE userId = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var followerIDs []uint64
+	for rows.Next() {
+		var followerID uint64
+		if err = rows.Scan(&followerID); err != nil {
+			return nil, err
+		}
+
+		followerIDs = append(followerIDs, followerID)
+	}
+
+	return followerIDs, rows.Err()
+}
+
+// CountFollowers retorna a quantidade de seguidores do usuário
+func (repo Users) CountFollowers(id uint64) (int, error) {
+	var count int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM followers WHERE userId = ?", id).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetFollowedCelebrities retorna os ids dos usuários seguidos pelo usuário que possuem mais
+// seguidores do que o limite informado
+func (repo Users) GetFollowedCelebrities(id uint64, threshold int) ([]uint64, error) {
+	rows, err := repo.db.Query(
+		`SELECT f.userId FROM followers AS f WHERE f.followerId = ?
+		AND (SELECT COUNT(*) FROM followers AS c WHERE c.userId = f.userId) > ?`,
+		id, threshold,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var celebrityIDs []uint64
+	for rows.Next() {
+		var celebrityID uint64
+		if err = rows.Scan(&celebrityID); err != nil {
+			return nil, err
+		}
+
+		celebrityIDs = append(celebrityIDs, celebrityID)
+	}
+
+	return celebrityIDs, rows.Err()
+}
+
+func scanEntries(rows *sql.Rows) ([]pagination.Cursor, error) {
+	var entries []pagination.Cursor
+	for rows.Next() {
+		var entry pagination.Cursor
+		if err := rows.Scan(&entry.CreatedAt, &entry.ID); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -2,9 +2,10 @@ package repository
 
 import (
 	"database/sql"
-	"fmt"
+	"encoding/json"
 
 	"api.devbook/src/model"
+	"api.devbook/src/pagination"
 )
 
 // Users representa um repositório de usuários
@@ -19,13 +20,13 @@ func NewRepositoryOfUsers(db *sql.DB) *Users {
 
 // Criar insere um usuário no banco de dados
 func (repo Users) Create(user model.User) (uint64, error) {
-	statement, err := repo.db.Prepare("INSERT INTO users (name, nick, email, password) VALUES (?, ?, ?, ?)")
+	statement, err := repo.db.Prepare("INSERT INTO users (name, nick, email, password, private) VALUES (?, ?, ?, ?, ?)")
 	if err != nil {
 		return 0, nil
 	}
 	defer statement.Close()
 
-	result, err := statement.Exec(user.Name, user.Nick, user.Email, user.Password)
+	result, err := statement.Exec(user.Name, user.Nick, user.Email, user.Password, user.Private)
 	if err != nil {
 		return 0, err
 	}
@@ -38,64 +39,188 @@ func (repo Users) Create(user model.User) (uint64, error) {
 	return uint64(userID), nil
 }
 
-// Get traz todos os usuários que atendem o filtro
-func (repo Users) GetAll(nameOrNick string) ([]model.User, error) {
-	nameOrNick = fmt.Sprintf("%%%s%%", nameOrNick)
+// GetAll traz uma página de todos os usuários, exceto os que possuem bloqueio com o usuário que está
+// buscando
+func (repo Users) GetAll(viewerID uint64, page pagination.Page) ([]model.User, string, error) {
+	after, order, cursorArgs := pageClauses("u.createdAt", "u.id", page)
+	args := append([]interface{}{viewerID, viewerID}, cursorArgs...)
 
 	rows, err := repo.db.Query(
-		"SELECT id, name, nick, email, createdAt FROM users WHERE name LIKE ? OR nick LIKE ?", nameOrNick, nameOrNick,
+		`SELECT `+userColumns+`, u.createdAt FROM users AS u
+		WHERE `+notBlockedClause("u.id")+after+order,
+		args...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
-	var users []model.User
-	for rows.Next() {
-		var user model.User
-		if err := rows.Scan(&user.ID, &user.Name, &user.Nick, &user.Email, &user.CreatedAt); err != nil {
-			return nil, err
-		}
-
-		users = append(users, user)
-	}
-
-	return users, nil
+	return scanUserPage(rows, page)
 }
 
-// GetByID traz o usuário conforme o id fornecido
+// GetByID traz o usuário conforme o id fornecido, com todos os campos do perfil
 func (repo Users) GetByID(id uint64) (model.User, error) {
-	row, err := repo.db.Query("SELECT id, name, nick, email, createdAt FROM users WHERE id = ?", id)
+	row, err := repo.db.Query(
+		`SELECT id, name, nick, email, private, bio, location, links, pronouns, birthday,
+		birthdayVisibility, avatar, header, createdAt FROM users WHERE id = ?`,
+		id,
+	)
 	if err != nil {
 		return model.User{}, err
 	}
 	defer row.Close()
 
-	var user model.User
+	var (
+		user     model.User
+		links    []byte
+		birthday sql.NullTime
+	)
 	if row.Next() {
-		if err := row.Scan(&user.ID, &user.Name, &user.Nick, &user.Email, &user.CreatedAt); err != nil {
+		if err := row.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Nick,
+			&user.Email,
+			&user.Private,
+			&user.Bio,
+			&user.Location,
+			&links,
+			&user.Pronouns,
+			&birthday,
+			&user.BirthdayVisibility,
+			&user.Avatar,
+			&user.Header,
+			&user.CreatedAt,
+		); err != nil {
 			return model.User{}, err
 		}
 	}
 
+	if len(links) > 0 {
+		if err = json.Unmarshal(links, &user.Links); err != nil {
+			return model.User{}, err
+		}
+	}
+
+	if birthday.Valid {
+		user.Birthday = birthday.Time.Format("2006-01-02")
+	}
+
 	return user, nil
 }
 
-// Update atualiza as informações de um usuário
-func (repo Users) Update(id uint64, user model.User) error {
-	statement, err := repo.db.Prepare("UPDATE users SET name = ?, nick = ?, email = ? WHERE id = ?")
+// GetCounts retorna os totais de seguidores, de usuários seguidos e de publicações do usuário
+func (repo Users) GetCounts(id uint64) (model.UserCounts, error) {
+	row, err := repo.db.Query(
+		`SELECT
+		(SELECT COUNT(*) FROM followers WHERE userId = ?),
+		(SELECT COUNT(*) FROM followers WHERE followerId = ?),
+		(SELECT COUNT(*) FROM publications AS p WHERE p.authorId = ? AND `+publishedClause+` AND `+notExpiredClause+`)`,
+		id, id, id,
+	)
+	if err != nil {
+		return model.UserCounts{}, err
+	}
+	defer row.Close()
+
+	var counts model.UserCounts
+	if row.Next() {
+		if err = row.Scan(&counts.Followers, &counts.Following, &counts.Publications); err != nil {
+			return model.UserCounts{}, err
+		}
+	}
+
+	return counts, nil
+}
+
+// UpdateProfile atualiza os campos do perfil de um usuário
+func (repo Users) UpdateProfile(id uint64, user model.User) error {
+	links, err := json.Marshal(user.Links)
+	if err != nil {
+		return err
+	}
+
+	var birthday sql.NullString
+	if user.Birthday != "" {
+		birthday = sql.NullString{String: user.Birthday, Valid: true}
+	}
+
+	statement, err := repo.db.Prepare(
+		`UPDATE users SET bio = ?, location = ?, links = ?, pronouns = ?, birthday = ?,
+		birthdayVisibility = ?, avatar = ?, header = ? WHERE id = ?`,
+	)
 	if err != nil {
 		return err
 	}
 	defer statement.Close()
 
-	if _, err := statement.Exec(user.Name, user.Nick, user.Email, id); err != nil {
+	if _, err = statement.Exec(
+		user.Bio,
+		user.Location,
+		links,
+		user.Pronouns,
+		birthday,
+		user.BirthdayVisibility,
+		user.Avatar,
+		user.Header,
+		id,
+	); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Update atualiza as informações de um usuário. Ao tornar a conta pública, as solicitações para
+// seguir pendentes são aprovadas e os ids de quem as fez são retornados
+func (repo Users) Update(id uint64, user model.User) ([]uint64, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(
+		"UPDATE users SET name = ?, nick = ?, email = ?, private = ? WHERE id = ?",
+		user.Name, user.Nick, user.Email, user.Private, id,
+	); err != nil {
+		return nil, err
+	}
+
+	if user.Private {
+		return nil, tx.Commit()
+	}
+
+	rows, err := tx.Query("SELECT requesterId FROM follow_requests WHERE userId = ? FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+
+	requesterIDs, err := scanIDs(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(requesterIDs) == 0 {
+		return nil, tx.Commit()
+	}
+
+	if _, err = tx.Exec(
+		`INSERT ignore INTO followers (userId, followerId)
+		SELECT userId, requesterId FROM follow_requests WHERE userId = ?`,
+		id,
+	); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.Exec("DELETE FROM follow_requests WHERE userId = ?", id); err != nil {
+		return nil, err
+	}
+
+	return requesterIDs, tx.Commit()
+}
+
 // Exclui o registro de um usuário do banco de dados
 func (repo Users) Delete(id uint64) error {
 	statement, err := repo.db.Prepare("DELETE FROM users WHERE id = ?")
@@ -111,6 +236,44 @@ func (repo Users) Delete(id uint64) error {
 	return nil
 }
 
+// UpdateProfileImage altera a referência do avatar ou da capa do usuário conforme a finalidade informada
+func (repo Users) UpdateProfileImage(id uint64, purpose, reference string) error {
+	column := "avatar"
+	if purpose == model.MediaHeader {
+		column = "header"
+	}
+
+	statement, err := repo.db.Prepare("UPDATE users SET " + column + " = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	if _, err = statement.Exec(reference, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IsAdmin verifica se o usuário é um administrador
+func (repo Users) IsAdmin(id uint64) (bool, error) {
+	row, err := repo.db.Query("SELECT admin FROM users WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	defer row.Close()
+
+	var admin bool
+	if row.Next() {
+		if err = row.Scan(&admin); err != nil {
+			return false, err
+		}
+	}
+
+	return admin, nil
+}
+
 // SearchByEmail busca um usuário pelo email informado e retorna o seu id e o hash da senha
 func (repo Users) SearchByEmail(email string) (model.User, error) {
 	row, err := repo.db.Query("SELECT id, password FROM users WHERE email = ?", email)
@@ -144,79 +307,92 @@ func (repo Users) Follow(id, followedID uint64) error {
 	return nil
 }
 
-// Unfollow permite que um usuário deixe de seguir outro
+// Unfollow permite que um usuário deixe de seguir outro, cancelando também uma solicitação pendente
 func (repo Users) Unfollow(id, followedID uint64) error {
-	statement, err := repo.db.Prepare("DELETE FROM followers WHERE userId = ? AND followerId = ?")
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return err
 	}
-	defer statement.Close()
+	defer tx.Rollback()
 
-	if _, err = statement.Exec(followedID, id); err != nil {
+	if _, err = tx.Exec("DELETE FROM followers WHERE userId = ? AND followerId = ?", followedID, id); err != nil {
 		return err
 	}
 
-	return nil
+	if _, err = tx.Exec("DELETE FROM follow_requests WHERE userId = ? AND requesterId = ?", followedID, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// Busca os seguidores de um usuário
-func (repo Users) GetAllFollowers(id uint64) ([]model.User, error) {
-	rows, err := repo.db.Query(
-		`SELECT u.id, u.name, u.nick, u.email, u.createdAt FROM followers AS f
-		INNER JOIN users AS u ON f.followerId = u.id WHERE userId = ?`,
-		id,
+// CanView verifica se o usuário pode ver o conteúdo de outro. Contas públicas podem ser vistas por
+// todos, enquanto contas privadas apenas pelo próprio dono e pelos seguidores aprovados. Retorna
+// sql.ErrNoRows caso o usuário não exista
+func (repo Users) CanView(viewerID, id uint64) (bool, error) {
+	row, err := repo.db.Query(
+		`SELECT u.private = false OR u.id = ? OR EXISTS (
+			SELECT 1 FROM followers AS f WHERE f.userId = u.id AND f.followerId = ?
+		) FROM users AS u WHERE u.id = ?`,
+		viewerID, viewerID, id,
 	)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	defer rows.Close()
-
-	var followers []model.User
-	for rows.Next() {
-		var follower model.User
+	defer row.Close()
 
-		err = rows.Scan(&follower.ID, &follower.Name, &follower.Nick, &follower.Email, &follower.CreatedAt)
-		if err != nil {
-			return nil, err
+	if !row.Next() {
+		if err = row.Err(); err != nil {
+			return false, err
 		}
 
-		followers = append(followers, follower)
+		return false, sql.ErrNoRows
 	}
 
-	return followers, nil
+	var canView bool
+	if err = row.Scan(&canView); err != nil {
+		return false, err
+	}
+
+	return canView, nil
 }
 
-// GetAllFollowing retorna todos os usuários que o usuário está seguindo conforme o id passado
-func (repo Users) GetAllFollowing(id uint64) ([]model.User, error) {
+// Busca uma página dos seguidores de um usuário, dos mais recentes para os mais antigos, ocultando os
+// que possuem bloqueio com o usuário que está buscando
+func (repo Users) GetAllFollowers(id, viewerID uint64, page pagination.Page) ([]model.User, string, error) {
+	after, order, cursorArgs := pageClauses("f.createdAt", "u.id", page)
+	args := append([]interface{}{id, viewerID, viewerID}, cursorArgs...)
+
 	rows, err := repo.db.Query(
-		`SELECT u.id, u.name, u.nick, u.email, u.createdAt FROM followers AS f
-		INNER JOIN users AS u ON f.userId = u.id WHERE followerId = ?`,
-		id,
+		`SELECT `+userColumns+`, f.createdAt FROM followers AS f
+		INNER JOIN users AS u ON f.followerId = u.id WHERE f.userId = ? AND `+notBlockedClause("u.id")+after+order,
+		args...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
-	var allUsersFollowing []model.User
-	for rows.Next() {
-		var userFollowing model.User
-
-		err = rows.Scan(
-			&userFollowing.ID,
-			&userFollowing.Name,
-			&userFollowing.Nick,
-			&userFollowing.Email,
-			&userFollowing.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
+	return scanUserPage(rows, page)
+}
+
+// GetAllFollowing retorna uma página dos usuários que o usuário está seguindo conforme o id passado,
+// ocultando os que possuem bloqueio com o usuário que está buscando
+func (repo Users) GetAllFollowing(id, viewerID uint64, page pagination.Page) ([]model.User, string, error) {
+	after, order, cursorArgs := pageClauses("f.createdAt", "u.id", page)
+	args := append([]interface{}{id, viewerID, viewerID}, cursorArgs...)
 
-		allUsersFollowing = append(allUsersFollowing, userFollowing)
+	rows, err := repo.db.Query(
+		`SELECT `+userColumns+`, f.createdAt FROM followers AS f
+		INNER JOIN users AS u ON f.userId = u.id WHERE f.followerId = ? AND `+notBlockedClause("u.id")+after+order,
+		args...,
+	)
+	if err != nil {
+		return nil, "", err
 	}
+	defer rows.Close()
 
-	return allUsersFollowing, nil
+	return scanUserPage(rows, page)
 }
 
 // SearchPasswordByUserID traz a senha de um usuário pelo id fornecido
@@ -251,3 +427,56 @@ func (repo Users) ChangePassword(id uint64, password string) error {
 
 	return nil
 }
+
+// GetIDByNick retorna o id do usuário com o nick informado, ou zero caso ele não exista
+func (repo Users) GetIDByNick(nick string) (uint64, error) {
+	row, err := repo.db.Query("SELECT id FROM users WHERE nick = ?", nick)
+	if err != nil {
+		return 0, err
+	}
+	defer row.Close()
+
+	var id uint64
+	if row.Next() {
+		if err = row.Scan(&id); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, row.Err()
+}
+
+// GetMentionable retorna o id e o nick dos usuários com os nicks informados que podem ser mencionados
+// pelo autor, ou seja, que não possuem bloqueio com ele
+func (repo Users) GetMentionable(authorID uint64, nicks []string) ([]model.User, error) {
+	if len(nicks) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(nicks)+2)
+	for _, nick := range nicks {
+		args = append(args, nick)
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT u.id, u.nick FROM users AS u
+		WHERE u.nick IN (`+placeholders(len(nicks))+`) AND `+notBlockedClause("u.id"),
+		append(args, authorID, authorID)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		if err = rows.Scan(&user.ID, &user.Nick); err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
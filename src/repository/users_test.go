@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestUpdateApprovesFollowRequests(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewRepositoryOfUsers(db)
+
+	userID := createTestUser(t, db)
+	requesterIDs := []uint64{createTestUser(t, db), createTestUser(t, db)}
+
+	user, err := repo.GetByID(userID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+
+	// Sem solicitações pendentes, a edição de uma conta pública é gravada normalmente
+	user.Name = "Outro nome"
+	if approved, err := repo.Update(userID, user); err != nil || len(approved) != 0 {
+		t.Fatalf("Update(pública) = %v, %v", approved, err)
+	}
+
+	if stored, err := repo.GetByID(userID); err != nil || stored.Name != user.Name {
+		t.Fatalf("GetByID = %q, %v, want %q", stored.Name, err, user.Name)
+	}
+
+	user.Private = true
+	if approved, err := repo.Update(userID, user); err != nil || len(approved) != 0 {
+		t.Fatalf("Update(privada) = %v, %v", approved, err)
+	}
+
+	for _, requesterID := range requesterIDs {
+		if err = repo.RequestFollow(requesterID, userID); err != nil {
+			t.Fatalf("RequestFollow: %v", err)
+		}
+	}
+
+	// Continuar privada mantém as solicitações pendentes
+	if approved, err := repo.Update(userID, user); err != nil || len(approved) != 0 {
+		t.Fatalf("Update(privada) = %v, %v", approved, err)
+	}
+
+	user.Private = false
+	approved, err := repo.Update(userID, user)
+	if err != nil {
+		t.Fatalf("Update(pública): %v", err)
+	}
+
+	sort.Slice(approved, func(i, j int) bool { return approved[i] < approved[j] })
+	if !reflect.DeepEqual(approved, requesterIDs) {
+		t.Errorf("aprovados = %v, want %v", approved, requesterIDs)
+	}
+
+	for _, requesterID := range requesterIDs {
+		if following, err := repo.IsFollowing(requesterID, userID); err != nil || !following {
+			t.Errorf("IsFollowing(%d) = %v, %v, want true", requesterID, following, err)
+		}
+	}
+}
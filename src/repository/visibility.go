@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"fmt"
+
+	"api.devbook/src/model"
+)
+
+// canViewClause retorna a condição SQL que permite ver o conteúdo de contas públicas, da própria
+// conta e das contas privadas seguidas pelo usuário que está consultando. A coluna informada deve
+// conter o id do dono do conteúdo e a condição espera o id do usuário que está consultando como
+// argumento duas vezes
+func canViewClause(column string) string {
+	return fmt.Sprintf(
+		`(%[1]s = ?
+		OR NOT EXISTS (SELECT 1 FROM users AS pu WHERE pu.id = %[1]s AND pu.private = true)
+		OR EXISTS (SELECT 1 FROM followers AS vf WHERE vf.userId = %[1]s AND vf.followerId = ?))`,
+		column,
+	)
+}
+
+// visibleClause retorna a condição SQL que mantém apenas as publicações cuja visibilidade permite que
+// o usuário que está consultando as veja: as públicas, as dele, as restritas aos seguidores de quem
+// ele segue e as restritas aos mencionados em que ele foi mencionado. A condição espera o id do
+// usuário que está consultando três vezes como argumento
+func visibleClause(alias string) string {
+	return fmt.Sprintf(
+		`(%[1]s.visibility = '%[2]s' OR %[1]s.authorId = ?
+		OR (%[1]s.visibility = '%[3]s' AND EXISTS (
+			SELECT 1 FROM followers AS pf WHERE pf.userId = %[1]s.authorId AND pf.followerId = ?
+		))
+		OR (%[1]s.visibility = '%[4]s' AND EXISTS (
+			SELECT 1 FROM publication_mentions AS vm WHERE vm.publicationId = %[1]s.id AND vm.userId = ?
+		)))`,
+		alias, model.VisibilityPublic, model.VisibilityFollowers, model.VisibilityMentioned,
+	)
+}
+
+// ViewableClause retorna a condição SQL, e os seus argumentos, que mantém apenas as publicações de
+// alias "p" que o usuário pode ver: sem bloqueio entre ele e o autor, de autores que ele não
+// silenciou, de contas que ele pode ver e com uma visibilidade que o inclui. É exportada para o
+// índice de busca do MySQL, que filtra as publicações na própria consulta
+func ViewableClause(viewerID uint64) (string, []interface{}) {
+	clause := notBlockedClause("p.authorId") + " AND " + notMutedClause("p.authorId") +
+		" AND " + canViewClause("p.authorId") + " AND " + visibleClause("p")
+
+	return clause, []interface{}{viewerID, viewerID, viewerID, viewerID, viewerID, viewerID, viewerID, viewerID}
+}
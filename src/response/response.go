@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 )
@@ -26,3 +27,22 @@ func Error(w http.ResponseWriter, statusCode int, err error) {
 		Error: err.Error(),
 	})
 }
+
+// Page retorna uma página de uma listagem dentro de um envelope com o cursor da próxima página,
+// que também é informado no cabeçalho Link
+func Page(w http.ResponseWriter, r *http.Request, data interface{}, nextCursor string) {
+	if nextCursor != "" {
+		query := r.URL.Query()
+		query.Set("cursor", nextCursor)
+
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, query.Encode()))
+	}
+
+	JSON(w, http.StatusOK, struct {
+		Data       interface{} `json:"data"`
+		NextCursor string      `json:"next_cursor,omitempty"`
+	}{
+		Data:       data,
+		NextCursor: nextCursor,
+	})
+}
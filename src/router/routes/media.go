@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"net/http"
+
+	"api.devbook/src/controller"
+)
+
+var mediaRoutes = []Route{
+	{
+		URI:          "/media",
+		Method:       http.MethodPost,
+		Func:         controller.UploadMedia,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/media/{key}",
+		Method:       http.MethodGet,
+		Func:         controller.ServeMedia,
+		RequiresAuth: false,
+	},
+}
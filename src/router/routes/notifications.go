@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"net/http"
+
+	"api.devbook/src/controller"
+)
+
+var notificationsRoutes = []Route{
+	{
+		URI:          "/notifications",
+		Method:       http.MethodGet,
+		Func:         controller.GetNotifications,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/notifications/read",
+		Method:       http.MethodPost,
+		Func:         controller.MarkAllNotificationsAsRead,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/notifications/{notificationId}/read",
+		Method:       http.MethodPost,
+		Func:         controller.MarkNotificationAsRead,
+		RequiresAuth: true,
+	},
+}
@@ -19,6 +19,24 @@ var publicationsRoutes = []Route{
 		Func:         controller.GetAllPublications,
 		RequiresAuth: true,
 	},
+	{
+		URI:          "/publications/feed",
+		Method:       http.MethodGet,
+		Func:         controller.GetFeed,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/search",
+		Method:       http.MethodGet,
+		Func:         controller.SearchPublications,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/drafts",
+		Method:       http.MethodGet,
+		Func:         controller.GetDrafts,
+		RequiresAuth: true,
+	},
 	{
 		URI:          "/publications/{publicationId}",
 		Method:       http.MethodGet,
@@ -43,6 +61,18 @@ var publicationsRoutes = []Route{
 		Func:         controller.GetAllPublicationsOfUser,
 		RequiresAuth: true,
 	},
+	{
+		URI:          "/publications/{publicationId}/reactions",
+		Method:       http.MethodPost,
+		Func:         controller.ReactToPublication,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/reactions",
+		Method:       http.MethodDelete,
+		Func:         controller.RemoveReaction,
+		RequiresAuth: true,
+	},
 	{
 		URI:          "/publications/{publicationId}/like",
 		Method:       http.MethodPost,
@@ -55,4 +85,70 @@ var publicationsRoutes = []Route{
 		Func:         controller.DislikePublication,
 		RequiresAuth: true,
 	},
+	{
+		URI:          "/publications/{publicationId}/repost",
+		Method:       http.MethodPost,
+		Func:         controller.RepostPublication,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/repost",
+		Method:       http.MethodDelete,
+		Func:         controller.UnrepostPublication,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/bookmark",
+		Method:       http.MethodPost,
+		Func:         controller.BookmarkPublication,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/bookmark",
+		Method:       http.MethodDelete,
+		Func:         controller.UnbookmarkPublication,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/pin",
+		Method:       http.MethodPost,
+		Func:         controller.PinPublication,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/pin",
+		Method:       http.MethodDelete,
+		Func:         controller.UnpinPublication,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/poll/votes",
+		Method:       http.MethodPost,
+		Func:         controller.VoteOnPoll,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/thread",
+		Method:       http.MethodGet,
+		Func:         controller.GetThread,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/thread",
+		Method:       http.MethodPost,
+		Func:         controller.ContinueThread,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/revisions",
+		Method:       http.MethodGet,
+		Func:         controller.GetRevisions,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/publications/{publicationId}/revisions/{revisionId}/restore",
+		Method:       http.MethodPost,
+		Func:         controller.RestoreRevision,
+		RequiresAuth: true,
+	},
 }
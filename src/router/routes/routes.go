@@ -20,6 +20,9 @@ func Config(r *mux.Router) *mux.Router {
 	routes := userRoutes
 	routes = append(routes, loginRoute)
 	routes = append(routes, publicationsRoutes...)
+	routes = append(routes, mediaRoutes...)
+	routes = append(routes, tagsRoutes...)
+	routes = append(routes, notificationsRoutes...)
 
 	for _, route := range routes {
 		if route.RequiresAuth {
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"net/http"
+
+	"api.devbook/src/controller"
+)
+
+var tagsRoutes = []Route{
+	{
+		URI:          "/tags/trending",
+		Method:       http.MethodGet,
+		Func:         controller.GetTrendingTags,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/tags/followed",
+		Method:       http.MethodGet,
+		Func:         controller.GetFollowedTags,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/tags/{tag}/publications",
+		Method:       http.MethodGet,
+		Func:         controller.GetPublicationsOfTag,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/tags/{tag}/follow",
+		Method:       http.MethodPost,
+		Func:         controller.FollowTag,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/tags/{tag}/unfollow",
+		Method:       http.MethodDelete,
+		Func:         controller.UnfollowTag,
+		RequiresAuth: true,
+	},
+}
@@ -19,6 +19,12 @@ var userRoutes = []Route{
 		Func:         controller.GetAllUsers,
 		RequiresAuth: true,
 	},
+	{
+		URI:          "/users/suggest",
+		Method:       http.MethodGet,
+		Func:         controller.SuggestUsers,
+		RequiresAuth: true,
+	},
 	{
 		URI:          "/users/{id}",
 		Method:       http.MethodGet,
@@ -31,6 +37,12 @@ var userRoutes = []Route{
 		Func:         controller.UpdateUser,
 		RequiresAuth: true,
 	},
+	{
+		URI:          "/users/{id}/profile",
+		Method:       http.MethodPatch,
+		Func:         controller.UpdateProfile,
+		RequiresAuth: true,
+	},
 	{
 		URI:          "/users/{id}",
 		Method:       http.MethodDelete,
@@ -67,4 +79,88 @@ var userRoutes = []Route{
 		Func:         controller.UpdatePassword,
 		RequiresAuth: true,
 	},
+	{
+		URI:          "/users/{id}/block",
+		Method:       http.MethodPost,
+		Func:         controller.BlockUser,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/unblock",
+		Method:       http.MethodDelete,
+		Func:         controller.UnblockUser,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/blocked",
+		Method:       http.MethodGet,
+		Func:         controller.GetBlockedUsers,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/mute",
+		Method:       http.MethodPost,
+		Func:         controller.MuteUser,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/unmute",
+		Method:       http.MethodDelete,
+		Func:         controller.UnmuteUser,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/muted",
+		Method:       http.MethodGet,
+		Func:         controller.GetMutedUsers,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/followRequests",
+		Method:       http.MethodGet,
+		Func:         controller.GetFollowRequests,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/followRequests/{requesterId}/approve",
+		Method:       http.MethodPost,
+		Func:         controller.ApproveFollowRequest,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/followRequests/{requesterId}/reject",
+		Method:       http.MethodPost,
+		Func:         controller.RejectFollowRequest,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/bookmarks",
+		Method:       http.MethodGet,
+		Func:         controller.GetBookmarks,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/collections",
+		Method:       http.MethodGet,
+		Func:         controller.GetCollections,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/collections",
+		Method:       http.MethodPost,
+		Func:         controller.CreateCollection,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/collections/{collectionId}",
+		Method:       http.MethodPut,
+		Func:         controller.UpdateCollection,
+		RequiresAuth: true,
+	},
+	{
+		URI:          "/users/{id}/collections/{collectionId}",
+		Method:       http.MethodDelete,
+		Func:         controller.DeleteCollection,
+		RequiresAuth: true,
+	},
 }
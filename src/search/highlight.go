@@ -0,0 +1,83 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetContext é a quantidade aproximada de bytes exibida antes do primeiro trecho encontrado
+const snippetContext = 40
+
+// Highlight retorna um trecho do texto, com até maxLength bytes, a partir das proximidades da primeira
+// palavra encontrada pela busca. O texto é escapado para HTML e as palavras encontradas são marcadas
+// com <mark>
+func Highlight(text string, query Query, maxLength int) string {
+	tokens := tokenize(text)
+
+	var matched []token
+	for _, token := range tokens {
+		if query.matches(token.text) {
+			matched = append(matched, token)
+		}
+	}
+
+	start := 0
+	if len(matched) > 0 && matched[0].start > snippetContext {
+		start = matched[0].start - snippetContext
+		// Começa o trecho no início de uma palavra
+		for _, token := range tokens {
+			if token.start >= start {
+				start = token.start
+				break
+			}
+		}
+	}
+
+	end := start + maxLength
+	if end >= len(text) {
+		end = len(text)
+	} else {
+		for end > start && !isBoundary(text, end) {
+			end--
+		}
+	}
+
+	var snippet strings.Builder
+	if start > 0 {
+		snippet.WriteString("…")
+	}
+
+	position := start
+	for _, token := range matched {
+		if token.start < position || token.end > end {
+			continue
+		}
+
+		snippet.WriteString(html.EscapeString(text[position:token.start]))
+		snippet.WriteString("<mark>")
+		snippet.WriteString(html.EscapeString(text[token.start:token.end]))
+		snippet.WriteString("</mark>")
+		position = token.end
+	}
+
+	snippet.WriteString(html.EscapeString(text[position:end]))
+	if end < len(text) {
+		snippet.WriteString("…")
+	}
+
+	return snippet.String()
+}
+
+// isBoundary informa se a posição está entre palavras, evitando cortar uma palavra ou um caractere.
+// Assim como em tokenize, as palavras são formadas por letras e dígitos
+func isBoundary(text string, position int) bool {
+	if !utf8.RuneStart(text[position]) {
+		return false
+	}
+
+	next, _ := utf8.DecodeRuneInString(text[position:])
+	previous, _ := utf8.DecodeLastRuneInString(text[:position])
+
+	return !isWordRune(next) || !isWordRune(previous)
+}
@@ -0,0 +1,62 @@
+package search
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		query     string
+		maxLength int
+		want      string
+	}{
+		{
+			name:      "marca as palavras encontradas",
+			text:      "Aprendendo Go com MySQL",
+			query:     "go",
+			maxLength: 100,
+			want:      "Aprendendo <mark>Go</mark> com MySQL",
+		},
+		{
+			name:      "não corta palavras acentuadas",
+			text:      "ação rápida demais",
+			query:     "ação",
+			maxLength: 12,
+			want:      "<mark>ação</mark> …",
+		},
+		{
+			name:      "pontuação separa palavras",
+			text:      "fim,começo",
+			query:     "fim",
+			maxLength: 5,
+			want:      "<mark>fim</mark>,…",
+		},
+		{
+			name:      "dígitos fazem parte da palavra",
+			text:      "versão 1234567",
+			query:     "versão",
+			maxLength: 11,
+			want:      "<mark>versão</mark> …",
+		},
+		{
+			name:      "escapa o html",
+			text:      "<b>negrito</b>",
+			query:     "negrito",
+			maxLength: 100,
+			want:      "&lt;b&gt;<mark>negrito</mark>&lt;/b&gt;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+			}
+
+			if got := Highlight(tt.text, query, tt.maxLength); got != tt.want {
+				t.Errorf("Highlight(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
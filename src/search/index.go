@@ -0,0 +1,69 @@
+package search
+
+import (
+	"time"
+
+	"api.devbook/src/markdown"
+	"api.devbook/src/model"
+)
+
+// MaxResults é a quantidade máxima de resultados considerados em uma busca. As páginas além desse
+// limite não são exibidas
+const MaxResults = 1000
+
+// Default é o índice de busca utilizado pela API, configurado na inicialização
+var Default Index
+
+// Document é o conteúdo de uma publicação mantido no índice
+type Document struct {
+	ID        uint64
+	AuthorID  uint64
+	Title     string
+	Content   string
+	CreatedAt time.Time
+}
+
+// Hit é uma publicação encontrada pela busca, com a sua relevância
+type Hit struct {
+	PublicationID uint64
+	Score         float64
+}
+
+// Index indexa as publicações e encontra as que correspondem a uma busca
+type Index interface {
+	// Add insere a publicação no índice, substituindo a versão anterior caso ela já exista
+	Add(document Document) error
+	// Remove retira a publicação do índice
+	Remove(publicationID uint64) error
+	// Search retorna até limit publicações que correspondem à busca, da mais relevante para a menos
+	// relevante
+	Search(query Query, limit int) ([]Hit, error)
+}
+
+// DocumentOf retorna o documento a ser indexado para a publicação, com o texto visível do conteúdo
+func DocumentOf(publication model.Publication) Document {
+	return Document{
+		ID:        publication.ID,
+		AuthorID:  publication.AuthorID,
+		Title:     publication.Title,
+		Content:   markdown.PlainText(publication.Content),
+		CreatedAt: publication.CreatedAt,
+	}
+}
+
+// filter informa se a publicação atende aos filtros de autor e de data da busca
+func (q Query) filter(document Document) bool {
+	if q.AuthorID != 0 && document.AuthorID != q.AuthorID {
+		return false
+	}
+
+	if !q.From.IsZero() && document.CreatedAt.Before(q.From) {
+		return false
+	}
+
+	if !q.To.IsZero() && !document.CreatedAt.Before(q.To) {
+		return false
+	}
+
+	return true
+}
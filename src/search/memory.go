@@ -0,0 +1,220 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// titleWeight é o peso das ocorrências no título em relação às ocorrências no conteúdo
+const titleWeight = 2
+
+// Memory é um índice invertido mantido na memória do processo, útil em desenvolvimento e em
+// instalações com uma única instância. O índice é perdido ao reiniciar e precisa ser carregado
+// novamente a partir do banco de dados
+type Memory struct {
+	mu        sync.RWMutex
+	documents map[uint64]memoryDocument
+	// postings guarda, para cada palavra, as posições em que ela aparece em cada publicação
+	postings map[string]map[uint64][]int
+}
+
+type memoryDocument struct {
+	Document
+	words []string
+	// titleLength é a quantidade de palavras do título, que ocupam as primeiras posições
+	titleLength int
+}
+
+// NewMemory cria um índice de busca vazio na memória
+func NewMemory() *Memory {
+	return &Memory{
+		documents: make(map[uint64]memoryDocument),
+		postings:  make(map[string]map[uint64][]int),
+	}
+}
+
+// Add insere a publicação no índice, substituindo a versão anterior caso ela já exista
+func (m *Memory) Add(document Document) error {
+	title := words(document.Title)
+	// A posição vazia entre o título e o conteúdo impede que uma frase seja encontrada atravessando
+	// os dois campos
+	all := append(append(title, ""), words(document.Content)...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(document.ID)
+
+	m.documents[document.ID] = memoryDocument{document, all, len(title)}
+	for position, word := range all {
+		if word == "" {
+			continue
+		}
+
+		if m.postings[word] == nil {
+			m.postings[word] = make(map[uint64][]int)
+		}
+		m.postings[word][document.ID] = append(m.postings[word][document.ID], position)
+	}
+
+	return nil
+}
+
+// Remove retira a publicação do índice
+func (m *Memory) Remove(publicationID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(publicationID)
+
+	return nil
+}
+
+func (m *Memory) remove(publicationID uint64) {
+	document, ok := m.documents[publicationID]
+	if !ok {
+		return
+	}
+
+	for _, word := range document.words {
+		delete(m.postings[word], publicationID)
+		if len(m.postings[word]) == 0 {
+			delete(m.postings, word)
+		}
+	}
+
+	delete(m.documents, publicationID)
+}
+
+// Search retorna as publicações que contêm todos os termos, prefixos e frases da busca, ordenadas
+// pela soma da frequência de cada palavra encontrada ponderada pela sua raridade
+func (m *Memory) Search(query Query, limit int) ([]Hit, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Cada requisito da busca é um conjunto de palavras aceitas, e a publicação precisa conter ao
+	// menos uma palavra de cada conjunto
+	var requirements [][]string
+	for _, term := range query.Terms {
+		requirements = append(requirements, []string{term})
+	}
+
+	for _, prefix := range query.Prefixes {
+		var expanded []string
+		for word := range m.postings {
+			if strings.HasPrefix(word, prefix) {
+				expanded = append(expanded, word)
+			}
+		}
+
+		requirements = append(requirements, expanded)
+	}
+
+	for _, phrase := range query.Phrases {
+		for _, word := range phrase {
+			requirements = append(requirements, []string{word})
+		}
+	}
+
+	scores := make(map[uint64]float64)
+	for i, requirement := range requirements {
+		matched := make(map[uint64]float64)
+		for _, word := range requirement {
+			idf := math.Log(1 + float64(len(m.documents))/float64(len(m.postings[word])))
+
+			for publicationID, positions := range m.postings[word] {
+				if _, ok := scores[publicationID]; i > 0 && !ok {
+					continue
+				}
+
+				matched[publicationID] += m.frequency(publicationID, positions) * idf
+			}
+		}
+
+		for publicationID, score := range scores {
+			if _, ok := matched[publicationID]; !ok {
+				delete(scores, publicationID)
+				continue
+			}
+
+			scores[publicationID] = score + matched[publicationID]
+		}
+
+		if i == 0 {
+			scores = matched
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for publicationID, score := range scores {
+		document := m.documents[publicationID]
+		if !query.filter(document.Document) || !m.containsPhrases(document, query.Phrases) {
+			continue
+		}
+
+		hits = append(hits, Hit{publicationID, score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+
+		return m.documents[hits[i].PublicationID].CreatedAt.After(m.documents[hits[j].PublicationID].CreatedAt)
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, nil
+}
+
+// frequency conta as ocorrências de uma palavra na publicação, com as do título valendo mais
+func (m *Memory) frequency(publicationID uint64, positions []int) float64 {
+	titleLength := m.documents[publicationID].titleLength
+
+	frequency := 0.0
+	for _, position := range positions {
+		if position < titleLength {
+			frequency += titleWeight
+			continue
+		}
+
+		frequency++
+	}
+
+	return frequency
+}
+
+// containsPhrases verifica se as palavras de cada frase aparecem em sequência na publicação
+func (m *Memory) containsPhrases(document memoryDocument, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		found := false
+		for _, start := range m.postings[phrase[0]][document.ID] {
+			if start+len(phrase) > len(document.words) {
+				continue
+			}
+
+			found = true
+			for offset, word := range phrase {
+				if document.words[start+offset] != word {
+					found = false
+					break
+				}
+			}
+
+			if found {
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"database/sql"
+	"strings"
+
+	"api.devbook/src/model"
+)
+
+// ViewableClause retorna a condição SQL, e os seus argumentos, que mantém apenas as publicações de
+// alias "p" que o usuário pode ver
+type ViewableClause func(viewerID uint64) (string, []interface{})
+
+// MySQL é um índice de busca apoiado no índice FULLTEXT da tabela de publicações. O próprio banco
+// mantém o índice atualizado, por isso Add e Remove não fazem nada. A comparação sem acentos depende
+// do collation da tabela
+type MySQL struct {
+	db       *sql.DB
+	viewable ViewableClause
+}
+
+// NewMySQL cria um índice de busca que consulta o banco de dados informado, filtrando os resultados
+// com a condição de viewable para que o limite de resultados conte apenas publicações visíveis
+func NewMySQL(db *sql.DB, viewable ViewableClause) *MySQL {
+	return &MySQL{db, viewable}
+}
+
+// Add não faz nada, pois o índice FULLTEXT é atualizado pelo banco de dados
+func (m *MySQL) Add(document Document) error {
+	return nil
+}
+
+// Remove não faz nada, pois o índice FULLTEXT é atualizado pelo banco de dados
+func (m *MySQL) Remove(publicationID uint64) error {
+	return nil
+}
+
+// Search busca as publicações com MATCH ... AGAINST no modo booleano, exigindo todos os termos e
+// ignorando rascunhos, publicações agendadas e expiradas, além das que o usuário que está buscando
+// não pode ver
+func (m *MySQL) Search(query Query, limit int) ([]Hit, error) {
+	expression := booleanExpression(query)
+
+	viewable, viewableArgs := m.viewable(query.ViewerID)
+
+	conditions := " AND " + viewable
+	args := append([]interface{}{expression, expression}, viewableArgs...)
+
+	if query.AuthorID != 0 {
+		conditions += " AND p.authorId = ?"
+		args = append(args, query.AuthorID)
+	}
+
+	if !query.From.IsZero() {
+		conditions += " AND p.createdAt >= ?"
+		args = append(args, query.From)
+	}
+
+	if !query.To.IsZero() {
+		conditions += " AND p.createdAt < ?"
+		args = append(args, query.To)
+	}
+
+	rows, err := m.db.Query(
+		`SELECT p.id, MATCH (p.title, p.content) AGAINST (? IN BOOLEAN MODE) AS score
+		FROM publications AS p
+		WHERE MATCH (p.title, p.content) AGAINST (? IN BOOLEAN MODE)
+		AND p.status = '`+model.StatusPublished+`' AND (p.expiresAt IS NULL OR p.expiresAt > current_timestamp())`+conditions+`
+		ORDER BY score DESC, p.createdAt DESC, p.id DESC LIMIT ?`,
+		append(args, limit)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []Hit
+	for rows.Next() {
+		var hit Hit
+		if err = rows.Scan(&hit.PublicationID, &hit.Score); err != nil {
+			return nil, err
+		}
+
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
+
+// booleanExpression monta a expressão do modo booleano do MySQL. Os termos contêm apenas letras e
+// dígitos, portanto não há operadores vindos do usuário
+func booleanExpression(query Query) string {
+	var parts []string
+
+	for _, term := range query.Terms {
+		parts = append(parts, "+"+term)
+	}
+
+	for _, prefix := range query.Prefixes {
+		parts = append(parts, "+"+prefix+"*")
+	}
+
+	for _, phrase := range query.Phrases {
+		parts = append(parts, `+"`+strings.Join(phrase, " ")+`"`)
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Query representa uma busca por publicações. Todos os termos, prefixos e frases precisam estar
+// presentes no título ou no conteúdo para que a publicação seja encontrada
+type Query struct {
+	// Terms são palavras que devem aparecer exatamente, ignorando maiúsculas e acentos
+	Terms []string
+	// Prefixes são inícios de palavras, informados na busca com um asterisco no final
+	Prefixes []string
+	// Phrases são sequências de palavras, informadas na busca entre aspas
+	Phrases [][]string
+
+	AuthorID uint64
+	From     time.Time
+	To       time.Time
+
+	// ViewerID é o usuário que está buscando, usado pelos índices que filtram na consulta as
+	// publicações que ele não pode ver
+	ViewerID uint64
+}
+
+// ParseQuery interpreta o texto da busca. Trechos entre aspas são frases, palavras terminadas em
+// asterisco são prefixos e as demais são termos
+func ParseQuery(text string) (Query, error) {
+	var query Query
+
+	for text = strings.TrimSpace(text); text != ""; text = strings.TrimSpace(text) {
+		if text[0] == '"' {
+			end := strings.IndexByte(text[1:], '"')
+			if end < 0 {
+				end = len(text) - 1
+			}
+
+			if phrase := words(text[1 : end+1]); len(phrase) > 0 {
+				query.Phrases = append(query.Phrases, phrase)
+			}
+
+			text = text[min(end+2, len(text)):]
+			continue
+		}
+
+		end := strings.IndexAny(text, " \t\n\"")
+		if end < 0 {
+			end = len(text)
+		}
+
+		word := text[:end]
+		text = text[end:]
+
+		parts := words(word)
+		if len(parts) == 0 {
+			continue
+		}
+
+		if strings.HasSuffix(word, "*") {
+			query.Terms = append(query.Terms, parts[:len(parts)-1]...)
+			query.Prefixes = append(query.Prefixes, parts[len(parts)-1])
+			continue
+		}
+
+		query.Terms = append(query.Terms, parts...)
+	}
+
+	if len(query.Terms) == 0 && len(query.Prefixes) == 0 && len(query.Phrases) == 0 {
+		return Query{}, errors.New("A busca deve conter ao menos uma palavra")
+	}
+
+	return query, nil
+}
+
+// matches informa se a palavra normalizada corresponde a algum termo, prefixo ou palavra de frase
+func (q Query) matches(word string) bool {
+	for _, term := range q.Terms {
+		if word == term {
+			return true
+		}
+	}
+
+	for _, prefix := range q.Prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+
+	for _, phrase := range q.Phrases {
+		for _, phraseWord := range phrase {
+			if word == phraseWord {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"api.devbook/src/text"
+)
+
+// token é uma palavra normalizada com a sua posição, em bytes, no texto original
+type token struct {
+	text  string
+	start int
+	end   int
+}
+
+// tokenize separa o texto em palavras normalizadas, formadas por letras e dígitos
+func tokenize(s string) []token {
+	var (
+		tokens  []token
+		current strings.Builder
+		start   = -1
+	)
+
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{current.String(), start, end})
+			current.Reset()
+			start = -1
+		}
+	}
+
+	for i, r := range s {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+
+			current.WriteRune(text.FoldRune(r))
+			continue
+		}
+
+		flush(i)
+	}
+	flush(len(s))
+
+	return tokens
+}
+
+// isWordRune informa se o caractere faz parte de uma palavra
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func words(s string) []string {
+	tokens := tokenize(s)
+
+	words := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		words = append(words, token.text)
+	}
+
+	return words
+}
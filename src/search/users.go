@@ -0,0 +1,174 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"api.devbook/src/model"
+	"api.devbook/src/text"
+)
+
+// Pontuações de cada tipo de correspondência na busca de usuários. A diferença entre as faixas é
+// maior que o bônus por seguir o usuário, que apenas reordena os resultados dentro de cada faixa
+const (
+	exactNickScore  = 1000
+	nickPrefixScore = 800
+	namePrefixScore = 600
+	containsScore   = 400
+	fuzzyScore      = 200
+	typoPenalty     = 50
+	followedBoost   = 100
+)
+
+// UserCandidate é um usuário avaliado pela busca de usuários
+type UserCandidate struct {
+	User     model.User
+	Followed bool
+}
+
+// RankUsers ordena os candidatos pela correspondência com o termo buscado: nick idêntico, nick
+// começando pelo termo, nome ou palavra do nome começando pelo termo, nick ou nome contendo o termo e,
+// quando fuzzy é verdadeiro, nick ou palavra do nome com poucos erros de digitação. Usuários seguidos
+// ficam à frente dentro de cada faixa e os candidatos sem correspondência são descartados
+func RankUsers(term string, candidates []UserCandidate, fuzzy bool) []model.User {
+	term = text.Fold(strings.TrimSpace(term))
+
+	type scored struct {
+		user  model.User
+		score int
+	}
+
+	var ranked []scored
+	for _, candidate := range candidates {
+		score := scoreUser(term, candidate.User, fuzzy)
+		if score == 0 {
+			continue
+		}
+
+		if candidate.Followed {
+			score += followedBoost
+		}
+
+		ranked = append(ranked, scored{candidate.User, score})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+
+		if len(ranked[i].user.Nick) != len(ranked[j].user.Nick) {
+			return len(ranked[i].user.Nick) < len(ranked[j].user.Nick)
+		}
+
+		return ranked[i].user.Nick < ranked[j].user.Nick
+	})
+
+	users := make([]model.User, 0, len(ranked))
+	for _, item := range ranked {
+		users = append(users, item.user)
+	}
+
+	return users
+}
+
+func scoreUser(term string, user model.User, fuzzy bool) int {
+	nick := text.Fold(user.Nick)
+	name := text.Fold(user.Name)
+	nameWords := words(user.Name)
+
+	switch {
+	case nick == term:
+		return exactNickScore
+	case strings.HasPrefix(nick, term):
+		return nickPrefixScore
+	case strings.HasPrefix(name, term) || hasWordWithPrefix(nameWords, term):
+		return namePrefixScore
+	case strings.Contains(nick, term) || strings.Contains(name, term):
+		return containsScore
+	}
+
+	if !fuzzy {
+		return 0
+	}
+
+	allowed := maxTypos(term)
+	if allowed == 0 {
+		return 0
+	}
+
+	typos := levenshtein(term, nick)
+	for _, word := range nameWords {
+		if distance := levenshtein(term, word); distance < typos {
+			typos = distance
+		}
+	}
+
+	if typos > allowed {
+		return 0
+	}
+
+	return fuzzyScore - typos*typoPenalty
+}
+
+func hasWordWithPrefix(words []string, prefix string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maxTypos é a quantidade de erros de digitação tolerada para o tamanho do termo. Termos curtos não
+// toleram erros, pois quase qualquer palavra curta estaria a um erro de distância
+func maxTypos(term string) int {
+	switch length := len([]rune(term)); {
+	case length <= 3:
+		return 0
+	case length <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// levenshtein calcula a quantidade mínima de inserções, remoções e substituições de caracteres
+// necessárias para transformar a em b
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+
+			current[j] = smallest(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+func smallest(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var validKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9]+)?$`)
+
+// Local armazena os arquivos em um diretório do disco local
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocal cria um armazenamento local no diretório informado, servido a partir do baseURL
+func NewLocal(dir, baseURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Local{dir, baseURL}, nil
+}
+
+// Save grava o conteúdo em um arquivo temporário e o renomeia, evitando leituras de arquivos incompletos
+func (l *Local) Save(key string, content io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(l.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// Open abre o arquivo do disco para leitura
+func (l *Local) Open(key string) (*Object, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Object{ReadSeekCloser: file, ModTime: info.ModTime(), Size: info.Size()}, nil
+}
+
+// Delete remove o arquivo do disco
+func (l *Local) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// URL retorna o endereço de onde o arquivo é servido pela API
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + key
+}
+
+// path valida a chave para impedir o acesso a arquivos fora do diretório de armazenamento
+func (l *Local) path(key string) (string, error) {
+	if !validKey.MatchString(key) {
+		return "", errors.New("Chave de arquivo inválida")
+	}
+
+	return filepath.Join(l.dir, key), nil
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound é retornado quando o arquivo solicitado não existe no armazenamento
+var ErrNotFound = errors.New("Arquivo não encontrado")
+
+// Default é o armazenamento utilizado pela API, configurado na inicialização
+var Default Storage
+
+// Storage representa um local onde os arquivos enviados pelos usuários são armazenados
+type Storage interface {
+	// Save grava o conteúdo com a chave informada, substituindo o arquivo existente
+	Save(key string, content io.Reader) error
+	// Open abre o arquivo com a chave informada para leitura
+	Open(key string) (*Object, error)
+	// Delete remove o arquivo com a chave informada, sem falhar caso ele não exista
+	Delete(key string) error
+	// URL retorna o endereço público do arquivo com a chave informada
+	URL(key string) string
+}
+
+// Object representa um arquivo aberto do armazenamento
+type Object struct {
+	io.ReadSeekCloser
+	ModTime time.Time
+	Size    int64
+}
@@ -0,0 +1,32 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// accents mapeia as letras acentuadas usadas em português para as suas versões sem acento
+var accents = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// Fold converte o texto para minúsculas e remove os acentos, permitindo comparações que ignoram
+// essas diferenças
+func Fold(s string) string {
+	return strings.Map(FoldRune, s)
+}
+
+// FoldRune converte o caractere para minúsculo e remove o seu acento
+func FoldRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if folded, ok := accents[r]; ok {
+		return folded
+	}
+
+	return r
+}
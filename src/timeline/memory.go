@@ -0,0 +1,106 @@
+package timeline
+
+import "sync"
+
+// Memory mantém as linhas do tempo na memória do processo. Os dados são perdidos ao reiniciar a API e
+// não são compartilhados entre instâncias, sendo reconstruídos a partir do banco de dados
+type Memory struct {
+	mu        sync.RWMutex
+	timelines map[uint64][]Entry
+}
+
+// NewMemory cria um armazenamento de linhas do tempo em memória
+func NewMemory() *Memory {
+	return &Memory{timelines: make(map[uint64][]Entry)}
+}
+
+// Exists informa se a linha do tempo do usuário já foi construída
+func (m *Memory) Exists(userID uint64) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.timelines[userID]
+	return ok, nil
+}
+
+// Build substitui a linha do tempo do usuário pelas publicações informadas
+func (m *Memory) Build(userID uint64, entries ...Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.timelines[userID] = trim(merge(entries))
+	return nil
+}
+
+// Add insere as publicações na linha do tempo do usuário, caso ela já tenha sido construída
+func (m *Memory) Add(userID uint64, entries ...Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timeline, ok := m.timelines[userID]
+	if !ok {
+		return nil
+	}
+
+	m.timelines[userID] = trim(merge(timeline, entries))
+	return nil
+}
+
+// trim descarta as publicações que excedem MaxLength. Uma linha do tempo construída nunca é nula,
+// mesmo sem publicações
+func trim(timeline []Entry) []Entry {
+	if len(timeline) > MaxLength {
+		timeline = timeline[:MaxLength]
+	}
+
+	if timeline == nil {
+		timeline = []Entry{}
+	}
+
+	return timeline
+}
+
+// Remove retira as publicações da linha do tempo do usuário
+func (m *Memory) Remove(userID uint64, publicationIDs ...uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timeline, ok := m.timelines[userID]
+	if !ok {
+		return nil
+	}
+
+	removed := make(map[uint64]bool, len(publicationIDs))
+	for _, publicationID := range publicationIDs {
+		removed[publicationID] = true
+	}
+
+	kept := timeline[:0]
+	for _, entry := range timeline {
+		if !removed[entry.ID] {
+			kept = append(kept, entry)
+		}
+	}
+
+	m.timelines[userID] = kept
+	return nil
+}
+
+// Range retorna até limit publicações posteriores ao cursor informado
+func (m *Memory) Range(userID uint64, after *Entry, limit int) ([]Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entries []Entry
+	for _, entry := range m.timelines[userID] {
+		if len(entries) == limit {
+			break
+		}
+
+		if after == nil || newer(*after, entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,175 @@
+package timeline
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Redis mantém as linhas do tempo em sorted sets de um servidor compatível com o Redis, permitindo
+// que sejam compartilhadas entre instâncias da API e preservadas ao reiniciar
+//
+// Cada publicação é um membro com o id preenchido com zeros à esquerda e pontuação igual à data de
+// criação em segundos. Como membros com a mesma pontuação são ordenados lexicograficamente, a ordem
+// do sorted set coincide com a ordem da linha do tempo
+type Redis struct {
+	client *respClient
+}
+
+// NewRedis cria um armazenamento de linhas do tempo no servidor do endereço informado
+func NewRedis(address string) (*Redis, error) {
+	store := &Redis{newRESPClient(address)}
+
+	if _, err := store.client.do("PING"); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Exists informa se a linha do tempo do usuário já foi construída. Como o Redis não mantém sorted
+// sets vazios, a construção é registrada em uma chave própria
+func (r *Redis) Exists(userID uint64) (bool, error) {
+	reply, err := r.client.do("EXISTS", builtKey(userID))
+	if err != nil {
+		return false, err
+	}
+
+	return reply == int64(1), nil
+}
+
+// buildScript substitui a linha do tempo pelas publicações informadas e a marca como construída
+const buildScript = `
+redis.call('DEL', KEYS[1])
+if #ARGV > 1 then
+	redis.call('ZADD', KEYS[1], unpack(ARGV, 2))
+	redis.call('ZREMRANGEBYRANK', KEYS[1], 0, ARGV[1])
+end
+redis.call('SET', KEYS[2], '1')
+return 1
+`
+
+// addScript insere as publicações apenas se a linha do tempo já foi construída. A verificação e a
+// inserção são feitas no servidor para que uma construção simultânea não as separe
+const addScript = `
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return 0
+end
+redis.call('ZADD', KEYS[1], unpack(ARGV, 2))
+redis.call('ZREMRANGEBYRANK', KEYS[1], 0, ARGV[1])
+return 1
+`
+
+// Build substitui a linha do tempo do usuário pelas publicações informadas e a marca como construída
+func (r *Redis) Build(userID uint64, entries ...Entry) error {
+	_, err := r.client.do(r.scriptArgs(buildScript, userID, entries)...)
+	return err
+}
+
+// Add insere as publicações na linha do tempo do usuário, caso ela já tenha sido construída, e
+// descarta as mais antigas
+func (r *Redis) Add(userID uint64, entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	_, err := r.client.do(r.scriptArgs(addScript, userID, entries)...)
+	return err
+}
+
+// scriptArgs monta a chamada do script com as chaves da linha do tempo, a posição até a qual as
+// publicações mais antigas são descartadas e os pares de pontuação e membro das publicações
+func (r *Redis) scriptArgs(script string, userID uint64, entries []Entry) []string {
+	args := []string{"EVAL", script, "2", key(userID), builtKey(userID), strconv.Itoa(-MaxLength - 1)}
+	for _, entry := range entries {
+		args = append(args, strconv.FormatInt(entry.CreatedAt.Unix(), 10), member(entry.ID))
+	}
+
+	return args
+}
+
+// Remove retira as publicações da linha do tempo do usuário
+func (r *Redis) Remove(userID uint64, publicationIDs ...uint64) error {
+	if len(publicationIDs) == 0 {
+		return nil
+	}
+
+	args := []string{"ZREM", key(userID)}
+	for _, publicationID := range publicationIDs {
+		args = append(args, member(publicationID))
+	}
+
+	_, err := r.client.do(args...)
+	return err
+}
+
+// Range retorna até limit publicações posteriores ao cursor informado. As publicações criadas no
+// mesmo segundo do cursor são lidas em lotes até que as já exibidas sejam ultrapassadas
+func (r *Redis) Range(userID uint64, after *Entry, limit int) ([]Entry, error) {
+	max := "+inf"
+	if after != nil {
+		max = strconv.FormatInt(after.CreatedAt.Unix(), 10)
+	}
+
+	var entries []Entry
+	for offset := 0; len(entries) < limit; offset += limit {
+		reply, err := r.client.do(
+			"ZREVRANGEBYSCORE", key(userID), max, "-inf", "WITHSCORES",
+			"LIMIT", strconv.Itoa(offset), strconv.Itoa(limit),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items, ok := reply.([]interface{})
+		if !ok || len(items)%2 != 0 {
+			return nil, fmt.Errorf("redis: resposta inesperada para ZREVRANGEBYSCORE: %v", reply)
+		}
+
+		for i := 0; i < len(items) && len(entries) < limit; i += 2 {
+			entry, err := parseEntry(items[i], items[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			if after == nil || newer(*after, entry) {
+				entries = append(entries, entry)
+			}
+		}
+
+		if len(items) < 2*limit {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+func parseEntry(memberReply, scoreReply interface{}) (Entry, error) {
+	memberValue, _ := memberReply.(string)
+	scoreValue, _ := scoreReply.(string)
+
+	id, err := strconv.ParseUint(memberValue, 10, 64)
+	if err != nil {
+		return Entry{}, fmt.Errorf("redis: membro inválido %q", memberValue)
+	}
+
+	score, err := strconv.ParseFloat(scoreValue, 64)
+	if err != nil {
+		return Entry{}, fmt.Errorf("redis: pontuação inválida %q", scoreValue)
+	}
+
+	return Entry{CreatedAt: time.Unix(int64(score), 0), ID: id}, nil
+}
+
+func key(userID uint64) string {
+	return fmt.Sprintf("timeline:%d", userID)
+}
+
+func builtKey(userID uint64) string {
+	return fmt.Sprintf("timeline:%d:built", userID)
+}
+
+func member(publicationID uint64) string {
+	return fmt.Sprintf("%020d", publicationID)
+}
@@ -0,0 +1,444 @@
+package timeline
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestRedis verifica o armazenamento em um servidor RESP local que implementa os comandos usados por
+// Redis. Com REDIS_TEST_ADDRESS definida, os mesmos testes também rodam em um servidor Redis real
+func TestRedis(t *testing.T) {
+	t.Run("servidor local", func(t *testing.T) {
+		testStore(t, func(t *testing.T) Store {
+			store, err := NewRedis(newRESPServer(t).address())
+			if err != nil {
+				t.Fatalf("NewRedis: %v", err)
+			}
+
+			return store
+		})
+	})
+
+	t.Run("redis", func(t *testing.T) {
+		address := os.Getenv("REDIS_TEST_ADDRESS")
+		if address == "" {
+			t.Skip("REDIS_TEST_ADDRESS não definida")
+		}
+
+		testStore(t, func(t *testing.T) Store {
+			store, err := NewRedis(address)
+			if err != nil {
+				t.Fatalf("NewRedis: %v", err)
+			}
+
+			for userID := uint64(firstUser); userID < firstUser+100; userID++ {
+				if _, err = store.client.do("DEL", key(userID), builtKey(userID)); err != nil {
+					t.Fatalf("DEL: %v", err)
+				}
+			}
+
+			return store
+		})
+	})
+}
+
+func TestRESPClientReconnects(t *testing.T) {
+	server := newRESPServer(t)
+	client := newRESPClient(server.address())
+
+	if _, err := client.do("PING"); err != nil {
+		t.Fatalf("PING: %v", err)
+	}
+
+	server.dropConnections()
+
+	// O primeiro comando após a queda pode falhar, mas a conexão deve ser reaberta em seguida
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err = client.do("PING"); err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		t.Fatalf("PING após a queda da conexão: %v", err)
+	}
+}
+
+func TestRESPClientServerError(t *testing.T) {
+	client := newRESPClient(newRESPServer(t).address())
+
+	_, err := client.do("UNKNOWN")
+
+	var serverErr respError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("do(UNKNOWN) = %v, want respError", err)
+	}
+
+	// Um erro do servidor não invalida a conexão
+	if reply, err := client.do("PING"); err != nil || reply != "PONG" {
+		t.Fatalf("PING = %v, %v", reply, err)
+	}
+}
+
+// respServer é um servidor RESP em memória com os comandos usados por Redis. Os scripts de Redis
+// são executados pelos mesmos passos em Go
+type respServer struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	conns   []net.Conn
+	zsets   map[string]map[string]float64
+	strings map[string]string
+}
+
+func newRESPServer(t *testing.T) *respServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+
+	server := &respServer{
+		listener: listener,
+		zsets:    make(map[string]map[string]float64),
+		strings:  make(map[string]string),
+	}
+
+	go server.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		server.dropConnections()
+	})
+
+	return server
+}
+
+func (s *respServer) address() string {
+	return s.listener.Addr().String()
+}
+
+func (s *respServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *respServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+func (s *respServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		reply := s.execute(args)
+		s.mu.Unlock()
+
+		if _, err = conn.Write([]byte(encodeReply(reply))); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "*"), "\r\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "$"), "\r\n"))
+		if err != nil {
+			return nil, err
+		}
+
+		data := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+
+		args[i] = string(data[:size])
+	}
+
+	return args, nil
+}
+
+type (
+	simpleString string
+	serverError  string
+)
+
+func encodeReply(reply interface{}) string {
+	switch value := reply.(type) {
+	case nil:
+		return "$-1\r\n"
+	case simpleString:
+		return "+" + string(value) + "\r\n"
+	case serverError:
+		return "-" + string(value) + "\r\n"
+	case int:
+		return fmt.Sprintf(":%d\r\n", value)
+	case string:
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case []string:
+		encoded := fmt.Sprintf("*%d\r\n", len(value))
+		for _, item := range value {
+			encoded += encodeReply(item)
+		}
+		return encoded
+	}
+
+	panic(fmt.Sprintf("resposta sem codificação: %#v", reply))
+}
+
+func (s *respServer) execute(args []string) interface{} {
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return simpleString("PONG")
+	case "EXISTS":
+		count := 0
+		for _, k := range args[1:] {
+			if s.exists(k) {
+				count++
+			}
+		}
+		return count
+	case "DEL":
+		count := 0
+		for _, k := range args[1:] {
+			if s.exists(k) {
+				count++
+			}
+			delete(s.zsets, k)
+			delete(s.strings, k)
+		}
+		return count
+	case "SET":
+		s.strings[args[1]] = args[2]
+		return simpleString("OK")
+	case "ZADD":
+		return s.zadd(args[1], args[2:])
+	case "ZREM":
+		count := 0
+		for _, m := range args[2:] {
+			if _, ok := s.zsets[args[1]][m]; ok {
+				delete(s.zsets[args[1]], m)
+				count++
+			}
+		}
+		s.dropEmpty(args[1])
+		return count
+	case "ZREMRANGEBYRANK":
+		return s.zremrangebyrank(args[1], args[2], args[3])
+	case "ZREVRANGEBYSCORE":
+		return s.zrevrangebyscore(args[1:])
+	case "EVAL":
+		return s.eval(args[1:])
+	}
+
+	return serverError("ERR unknown command '" + args[0] + "'")
+}
+
+func (s *respServer) exists(k string) bool {
+	_, isSet := s.zsets[k]
+	_, isString := s.strings[k]
+	return isSet || isString
+}
+
+func (s *respServer) dropEmpty(k string) {
+	if len(s.zsets[k]) == 0 {
+		delete(s.zsets, k)
+	}
+}
+
+func (s *respServer) zadd(k string, pairs []string) interface{} {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return serverError("ERR syntax error")
+	}
+
+	if s.zsets[k] == nil {
+		s.zsets[k] = make(map[string]float64)
+	}
+
+	added := 0
+	for i := 0; i < len(pairs); i += 2 {
+		score, err := strconv.ParseFloat(pairs[i], 64)
+		if err != nil {
+			return serverError("ERR value is not a valid float")
+		}
+
+		if _, ok := s.zsets[k][pairs[i+1]]; !ok {
+			added++
+		}
+		s.zsets[k][pairs[i+1]] = score
+	}
+
+	return added
+}
+
+// sorted retorna os membros em ordem crescente de pontuação e, depois, de membro
+func (s *respServer) sorted(k string) []string {
+	members := make([]string, 0, len(s.zsets[k]))
+	for m := range s.zsets[k] {
+		members = append(members, m)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		a, b := s.zsets[k][members[i]], s.zsets[k][members[j]]
+		if a != b {
+			return a < b
+		}
+		return members[i] < members[j]
+	})
+
+	return members
+}
+
+func (s *respServer) zremrangebyrank(k, startArg, stopArg string) interface{} {
+	members := s.sorted(k)
+
+	start, _ := strconv.Atoi(startArg)
+	stop, _ := strconv.Atoi(stopArg)
+	if start < 0 {
+		start += len(members)
+	}
+	if stop < 0 {
+		stop += len(members)
+	}
+
+	removed := 0
+	for i := start; i <= stop && i < len(members); i++ {
+		if i >= 0 {
+			delete(s.zsets[k], members[i])
+			removed++
+		}
+	}
+
+	s.dropEmpty(k)
+	return removed
+}
+
+func (s *respServer) zrevrangebyscore(args []string) interface{} {
+	parse := func(value string) float64 {
+		switch value {
+		case "+inf":
+			return math.Inf(1)
+		case "-inf":
+			return math.Inf(-1)
+		}
+
+		score, _ := strconv.ParseFloat(value, 64)
+		return score
+	}
+
+	k, max, min := args[0], parse(args[1]), parse(args[2])
+
+	withScores, offset, count := false, 0, -1
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "WITHSCORES":
+			withScores = true
+		case "LIMIT":
+			offset, _ = strconv.Atoi(args[i+1])
+			count, _ = strconv.Atoi(args[i+2])
+			i += 2
+		}
+	}
+
+	members := s.sorted(k)
+
+	var reply []string
+	skipped := 0
+	for i := len(members) - 1; i >= 0 && count != 0; i-- {
+		score := s.zsets[k][members[i]]
+		if score > max || score < min {
+			continue
+		}
+
+		if skipped < offset {
+			skipped++
+			continue
+		}
+
+		reply = append(reply, members[i])
+		if withScores {
+			reply = append(reply, strconv.FormatFloat(score, 'f', -1, 64))
+		}
+		count--
+	}
+
+	if reply == nil {
+		reply = []string{}
+	}
+
+	return reply
+}
+
+// eval executa os scripts de Redis, reproduzindo os comandos que eles fazem
+func (s *respServer) eval(args []string) interface{} {
+	script, keys, argv := args[0], args[2:4], args[4:]
+
+	switch script {
+	case buildScript:
+		s.execute([]string{"DEL", keys[0]})
+		if len(argv) > 1 {
+			s.zadd(keys[0], argv[1:])
+			s.zremrangebyrank(keys[0], "0", argv[0])
+		}
+		s.strings[keys[1]] = "1"
+		return 1
+	case addScript:
+		if !s.exists(keys[1]) {
+			return 0
+		}
+		s.zadd(keys[0], argv[1:])
+		s.zremrangebyrank(keys[0], "0", argv[0])
+		return 1
+	}
+
+	return serverError("NOSCRIPT script desconhecido")
+}
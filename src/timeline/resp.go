@@ -0,0 +1,145 @@
+package timeline
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// respClient é um cliente mínimo do protocolo RESP, usado pelo Redis e por servidores compatíveis.
+// Os comandos são enviados por uma única conexão, reaberta quando ocorre um erro de rede
+type respClient struct {
+	address string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// respError representa um erro retornado pelo servidor em resposta a um comando
+type respError string
+
+func (e respError) Error() string {
+	return "redis: " + string(e)
+}
+
+func newRESPClient(address string) *respClient {
+	return &respClient{address: address, timeout: 5 * time.Second}
+}
+
+// do envia o comando e retorna a resposta como string, int64, nil ou []interface{}
+func (c *respClient) do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		conn, err := net.DialTimeout("tcp", c.address, c.timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		c.conn = conn
+		c.reader = bufio.NewReader(conn)
+	}
+
+	reply, err := c.roundTrip(args)
+	if err != nil {
+		var serverErr respError
+		if !errors.As(err, &serverErr) {
+			c.conn.Close()
+			c.conn = nil
+		}
+
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (c *respClient) roundTrip(args []string) (interface{}, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+
+	command := make([]byte, 0, 64)
+	command = append(command, fmt.Sprintf("*%d\r\n", len(args))...)
+	for _, arg := range args {
+		command = append(command, fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)...)
+	}
+
+	if _, err := c.conn.Write(command); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+func (c *respClient) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: resposta malformada")
+	}
+
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, respError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		if size < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, size+2)
+		if _, err = io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		if count < 0 {
+			return nil, nil
+		}
+
+		items := make([]interface{}, count)
+		for i := range items {
+			// Os erros de itens individuais são devolvidos sem interromper a leitura do restante
+			if items[i], err = c.readReply(); err != nil {
+				var serverErr respError
+				if !errors.As(err, &serverErr) {
+					return nil, err
+				}
+
+				items[i] = err
+			}
+		}
+
+		return items, nil
+	}
+
+	return nil, fmt.Errorf("redis: tipo de resposta desconhecido %q", kind)
+}
@@ -0,0 +1,99 @@
+package timeline
+
+import (
+	"database/sql"
+
+	"api.devbook/src/config"
+	"api.devbook/src/pagination"
+	"api.devbook/src/repository"
+)
+
+// source reúne as consultas ao banco de dados usadas para distribuir e ler as linhas do tempo,
+// permitindo que as regras de montagem sejam verificadas sem um banco de dados
+type source interface {
+	// TimelineEntries retorna as publicações mais recentes do usuário, de quem ele segue e das
+	// hashtags que ele segue
+	TimelineEntries(userID uint64, limit int) ([]Entry, error)
+	// EntriesOfAuthors retorna as publicações dos autores posteriores ao cursor da página, com um
+	// item além do limite
+	EntriesOfAuthors(authorIDs []uint64, page pagination.Page) ([]Entry, error)
+	// EntriesOfTags retorna as publicações com as hashtags posteriores ao cursor da página, com um
+	// item além do limite
+	EntriesOfTags(tagIDs []uint64, page pagination.Page) ([]Entry, error)
+	// EntriesOnlyOfTag retorna as publicações que estão na linha do tempo do usuário apenas por
+	// causa da hashtag
+	EntriesOnlyOfTag(userID, tagID uint64, limit int) ([]Entry, error)
+	// EntriesOnlyOfAuthor retorna as publicações do autor que não têm nenhuma hashtag seguida pelo
+	// usuário
+	EntriesOnlyOfAuthor(userID, authorID uint64, limit int) ([]Entry, error)
+	// CountFollowers retorna a quantidade de seguidores do usuário
+	CountFollowers(userID uint64) (int, error)
+	// FollowerIDs retorna os seguidores do usuário
+	FollowerIDs(userID uint64) ([]uint64, error)
+	// FollowedCelebrities retorna os usuários seguidos que são celebridades
+	FollowedCelebrities(userID uint64) ([]uint64, error)
+	// TagFollowerIDs retorna os seguidores das hashtags que não são populares
+	TagFollowerIDs(tags []string) ([]uint64, error)
+	// FollowedPopularTags retorna as hashtags seguidas que são populares
+	FollowedPopularTags(userID uint64) ([]uint64, error)
+}
+
+// dbSource é a source usada pela API, que consulta os repositórios
+type dbSource struct {
+	publications *repository.Publications
+	users        *repository.Users
+	tags         *repository.Tags
+}
+
+func newDBSource(db *sql.DB) dbSource {
+	return dbSource{
+		publications: repository.NewRepositoryOfPublications(db),
+		users:        repository.NewRepositoryOfUsers(db),
+		tags:         repository.NewRepositoryOfTags(db),
+	}
+}
+
+func (s dbSource) TimelineEntries(userID uint64, limit int) ([]Entry, error) {
+	return s.publications.GetTimelineEntries(userID, limit)
+}
+
+func (s dbSource) EntriesOfAuthors(authorIDs []uint64, page pagination.Page) ([]Entry, error) {
+	return s.publications.GetEntriesOfAuthors(authorIDs, page)
+}
+
+func (s dbSource) EntriesOfTags(tagIDs []uint64, page pagination.Page) ([]Entry, error) {
+	return s.publications.GetEntriesOfTags(tagIDs, page)
+}
+
+func (s dbSource) EntriesOnlyOfTag(userID, tagID uint64, limit int) ([]Entry, error) {
+	return s.publications.GetEntriesOnlyOfTag(userID, tagID, limit)
+}
+
+func (s dbSource) EntriesOnlyOfAuthor(userID, authorID uint64, limit int) ([]Entry, error) {
+	return s.publications.GetEntriesOnlyOfAuthor(userID, authorID, limit)
+}
+
+func (s dbSource) CountFollowers(userID uint64) (int, error) {
+	return s.users.CountFollowers(userID)
+}
+
+func (s dbSource) FollowerIDs(userID uint64) ([]uint64, error) {
+	return s.users.GetFollowerIDs(userID)
+}
+
+func (s dbSource) FollowedCelebrities(userID uint64) ([]uint64, error) {
+	return s.users.GetFollowedCelebrities(userID, config.CelebrityThreshold)
+}
+
+func (s dbSource) TagFollowerIDs(tags []string) ([]uint64, error) {
+	tagIDs, err := s.tags.GetIDsByNames(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tags.GetFollowerIDs(tagIDs, config.CelebrityThreshold)
+}
+
+func (s dbSource) FollowedPopularTags(userID uint64) ([]uint64, error) {
+	return s.tags.GetFollowedPopular(userID, config.CelebrityThreshold)
+}
@@ -0,0 +1,65 @@
+package timeline
+
+import (
+	"sort"
+
+	"api.devbook/src/pagination"
+)
+
+// MaxLength é a quantidade máxima de publicações mantidas na linha do tempo de cada usuário. As
+// páginas além desse limite não são exibidas
+const MaxLength = 800
+
+// Default é o armazenamento das linhas do tempo utilizado pela API, configurado na inicialização
+var Default Store
+
+// Entry identifica uma publicação na linha do tempo pela data de criação e pelo id, a mesma chave
+// usada na paginação
+type Entry = pagination.Cursor
+
+// Store armazena as linhas do tempo pré-calculadas dos usuários, ordenadas da publicação mais
+// recente para a mais antiga
+type Store interface {
+	// Exists informa se a linha do tempo do usuário já foi construída
+	Exists(userID uint64) (bool, error)
+	// Build substitui a linha do tempo do usuário pelas publicações informadas, que podem ser
+	// nenhuma, e a marca como construída
+	Build(userID uint64, entries ...Entry) error
+	// Add insere as publicações na linha do tempo do usuário, descartando as mais antigas que
+	// excederem MaxLength. Linhas do tempo que ainda não foram construídas não são alteradas, para
+	// que não fiquem parciais
+	Add(userID uint64, entries ...Entry) error
+	// Remove retira as publicações da linha do tempo do usuário
+	Remove(userID uint64, publicationIDs ...uint64) error
+	// Range retorna até limit publicações posteriores ao cursor informado, ou as mais recentes
+	// quando o cursor é nulo
+	Range(userID uint64, after *Entry, limit int) ([]Entry, error)
+}
+
+// newer informa se a entrada a vem antes da entrada b na linha do tempo
+func newer(a, b Entry) bool {
+	if a.CreatedAt.Equal(b.CreatedAt) {
+		return a.ID > b.ID
+	}
+
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// merge une as listas de entradas em ordem, descartando as repetidas
+func merge(lists ...[]Entry) []Entry {
+	seen := make(map[uint64]bool)
+
+	var merged []Entry
+	for _, list := range lists {
+		for _, entry := range list {
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				merged = append(merged, entry)
+			}
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool { return newer(merged[i], merged[j]) })
+
+	return merged
+}
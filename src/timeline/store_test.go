@@ -0,0 +1,173 @@
+package timeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// firstUser é somado aos ids dos usuários dos testes de armazenamento, para que não coincidam com
+// linhas do tempo reais quando os testes usam um servidor Redis compartilhado
+const firstUser = 1 << 40
+
+// at retorna a entrada da publicação criada no segundo informado, a precisão guardada pelo Redis
+func at(second int, id uint64) Entry {
+	return Entry{CreatedAt: base.Add(time.Duration(second) * time.Second), ID: id}
+}
+
+// rangeIDs retorna os ids de todas as publicações da linha do tempo lidas em páginas do tamanho informado
+func rangeIDs(t *testing.T, store Store, userID uint64, limit int) []uint64 {
+	t.Helper()
+
+	var (
+		ids   []uint64
+		after *Entry
+	)
+
+	for {
+		entries, err := store.Range(userID, after, limit)
+		if err != nil {
+			t.Fatalf("Range: %v", err)
+		}
+
+		if len(entries) > limit {
+			t.Fatalf("Range retornou %d entradas, limite %d", len(entries), limit)
+		}
+
+		ids = append(ids, entryIDs(entries)...)
+		if len(entries) < limit {
+			return ids
+		}
+
+		last := entries[len(entries)-1]
+		after = &last
+	}
+}
+
+// testStore verifica o comportamento que a API espera de qualquer armazenamento de linhas do tempo
+func testStore(t *testing.T, newStore func(t *testing.T) Store) {
+	must := func(t *testing.T, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exists := func(t *testing.T, store Store, userID uint64) bool {
+		t.Helper()
+
+		built, err := store.Exists(userID)
+		must(t, err)
+		return built
+	}
+
+	t.Run("linha do tempo vazia construída", func(t *testing.T) {
+		store, user := newStore(t), uint64(firstUser+1)
+
+		if exists(t, store, user) {
+			t.Fatal("Exists antes da construção = true")
+		}
+
+		must(t, store.Build(user))
+
+		if !exists(t, store, user) {
+			t.Fatal("Exists de uma linha do tempo vazia construída = false")
+		}
+
+		if got := rangeIDs(t, store, user, 10); len(got) != 0 {
+			t.Errorf("Range = %v, want vazio", got)
+		}
+	})
+
+	t.Run("Add não cria linhas do tempo parciais", func(t *testing.T) {
+		store, user := newStore(t), uint64(firstUser+2)
+
+		must(t, store.Add(user, at(1, 1)))
+
+		if exists(t, store, user) {
+			t.Fatal("Exists após Add sem construção = true")
+		}
+
+		must(t, store.Build(user, at(2, 2)))
+
+		if got, want := rangeIDs(t, store, user, 10), []uint64{2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Range = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Add sem repetir publicações", func(t *testing.T) {
+		store, user := newStore(t), uint64(firstUser+3)
+
+		must(t, store.Build(user, at(1, 1), at(3, 3)))
+		must(t, store.Add(user, at(2, 2), at(3, 3)))
+		must(t, store.Add(user, at(1, 1)))
+
+		if got, want := rangeIDs(t, store, user, 10), []uint64{3, 2, 1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Range = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Build substitui a linha do tempo", func(t *testing.T) {
+		store, user := newStore(t), uint64(firstUser+4)
+
+		must(t, store.Build(user, at(1, 1), at(2, 2)))
+		must(t, store.Build(user, at(3, 3)))
+
+		if got, want := rangeIDs(t, store, user, 10), []uint64{3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Range = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		store, user := newStore(t), uint64(firstUser+5)
+
+		must(t, store.Build(user, at(1, 1), at(2, 2), at(3, 3)))
+		must(t, store.Remove(user, 2, 4))
+		must(t, store.Remove(firstUser+99, 1))
+
+		if got, want := rangeIDs(t, store, user, 10), []uint64{3, 1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Range = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("descarta as mais antigas além de MaxLength", func(t *testing.T) {
+		store, user := newStore(t), uint64(firstUser+6)
+
+		entries := make([]Entry, 0, MaxLength)
+		for id := uint64(1); id <= MaxLength; id++ {
+			entries = append(entries, at(int(id), id))
+		}
+
+		must(t, store.Build(user, entries...))
+		must(t, store.Add(user, at(MaxLength+1, MaxLength+1), at(MaxLength+2, MaxLength+2)))
+
+		got := rangeIDs(t, store, user, MaxLength+10)
+		if len(got) != MaxLength {
+			t.Fatalf("len(Range) = %d, want %d", len(got), MaxLength)
+		}
+
+		if got[0] != MaxLength+2 || got[len(got)-1] != 3 {
+			t.Errorf("Range vai de %d a %d, want de %d a 3", got[0], got[len(got)-1], MaxLength+2)
+		}
+	})
+
+	t.Run("cursor com publicações do mesmo segundo", func(t *testing.T) {
+		store, user := newStore(t), uint64(firstUser+7)
+
+		// Ids de tamanhos diferentes garantem que a ordem não depende da representação em texto
+		must(t, store.Build(user,
+			at(1, 1), at(2, 9), at(2, 10), at(2, 11), at(2, 100), at(2, 5), at(2, 7), at(3, 2),
+		))
+
+		want := []uint64{2, 100, 11, 10, 9, 7, 5, 1}
+		for _, limit := range []int{1, 2, 3, 4, 8, 20} {
+			if got := rangeIDs(t, store, user, limit); !reflect.DeepEqual(got, want) {
+				t.Errorf("limit %d: Range = %v, want %v", limit, got, want)
+			}
+		}
+	})
+}
+
+func TestMemory(t *testing.T) {
+	testStore(t, func(t *testing.T) Store { return NewMemory() })
+}
@@ -0,0 +1,292 @@
+package timeline
+
+import (
+	"database/sql"
+
+	"api.devbook/src/config"
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+	"api.devbook/src/repository"
+)
+
+// backfillLength é a quantidade de publicações recentes de um usuário inseridas na linha do tempo
+// de quem passa a segui-lo
+const backfillLength = 50
+
+// OnPublish distribui a publicação para a linha do tempo do autor, para as dos seguidores do autor,
+// caso ele não seja uma celebridade, e para as dos seguidores das suas hashtags, exceto as populares.
+// As publicações de celebridades e de hashtags populares são buscadas no momento da leitura. Chamar
+// novamente para a mesma publicação, como após uma edição que altere as hashtags, não a duplica
+func OnPublish(db *sql.DB, publication model.Publication) error {
+	return onPublish(newDBSource(db), publication)
+}
+
+func onPublish(src source, publication model.Publication) error {
+	entry := Entry{CreatedAt: publication.CreatedAt, ID: publication.ID}
+
+	if err := Default.Add(publication.AuthorID, entry); err != nil {
+		return err
+	}
+
+	followers, err := src.CountFollowers(publication.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	var followerIDs []uint64
+	if followers <= config.CelebrityThreshold {
+		if followerIDs, err = src.FollowerIDs(publication.AuthorID); err != nil {
+			return err
+		}
+	}
+
+	tagFollowerIDs, err := src.TagFollowerIDs(publication.Tags)
+	if err != nil {
+		return err
+	}
+
+	for _, followerID := range append(followerIDs, tagFollowerIDs...) {
+		if err = Default.Add(followerID, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OnDelete retira a publicação das linhas do tempo do autor, dos seus seguidores e dos seguidores das
+// suas hashtags
+func OnDelete(db *sql.DB, publication model.Publication) error {
+	return onDelete(newDBSource(db), publication)
+}
+
+func onDelete(src source, publication model.Publication) error {
+	if err := Default.Remove(publication.AuthorID, publication.ID); err != nil {
+		return err
+	}
+
+	followerIDs, err := src.FollowerIDs(publication.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	tagFollowerIDs, err := src.TagFollowerIDs(publication.Tags)
+	if err != nil {
+		return err
+	}
+
+	for _, followerID := range append(followerIDs, tagFollowerIDs...) {
+		if err = Default.Remove(followerID, publication.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OnUntag retira a publicação editada das linhas do tempo dos seguidores das hashtags removidas dela,
+// exceto do autor e de quem continua a recebê-la por seguir o autor ou uma das hashtags que restaram
+func OnUntag(db *sql.DB, publication model.Publication, removed []string) error {
+	return onUntag(newDBSource(db), publication, removed)
+}
+
+func onUntag(src source, publication model.Publication, removed []string) error {
+	if len(removed) == 0 {
+		return nil
+	}
+
+	removedFollowerIDs, err := src.TagFollowerIDs(removed)
+	if err != nil || len(removedFollowerIDs) == 0 {
+		return err
+	}
+
+	followerIDs, err := src.FollowerIDs(publication.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	tagFollowerIDs, err := src.TagFollowerIDs(publication.Tags)
+	if err != nil {
+		return err
+	}
+
+	kept := map[uint64]bool{publication.AuthorID: true}
+	for _, userID := range append(followerIDs, tagFollowerIDs...) {
+		kept[userID] = true
+	}
+
+	for _, userID := range removedFollowerIDs {
+		if kept[userID] {
+			continue
+		}
+
+		if err = Default.Remove(userID, publication.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OnFollow insere as publicações recentes do usuário seguido na linha do tempo do seguidor
+func OnFollow(db *sql.DB, followerID, followedID uint64) error {
+	return onFollow(newDBSource(db), followerID, followedID)
+}
+
+func onFollow(src source, followerID, followedID uint64) error {
+	entries, err := src.EntriesOfAuthors([]uint64{followedID}, pagination.Page{Limit: backfillLength - 1})
+	if err != nil {
+		return err
+	}
+
+	return Default.Add(followerID, entries...)
+}
+
+// OnUnfollow retira da linha do tempo do seguidor as publicações do usuário que deixou de ser seguido,
+// exceto as que continuam nela por causa de uma hashtag que ele segue. Quando o usuário deixa de
+// ser uma celebridade, as suas publicações, que até então eram buscadas na leitura, são inseridas nas
+// linhas do tempo dos seguidores restantes
+func OnUnfollow(db *sql.DB, followerID, followedID uint64) error {
+	return onUnfollow(newDBSource(db), followerID, followedID)
+}
+
+func onUnfollow(src source, followerID, followedID uint64) error {
+	entries, err := src.EntriesOnlyOfAuthor(followerID, followedID, MaxLength)
+	if err != nil {
+		return err
+	}
+
+	if err = Default.Remove(followerID, entryIDs(entries)...); err != nil {
+		return err
+	}
+
+	// Cada remoção tira um seguidor, então o limite é cruzado quando a contagem chega exatamente a ele
+	followers, err := src.CountFollowers(followedID)
+	if err != nil || followers != config.CelebrityThreshold {
+		return err
+	}
+
+	if entries, err = src.EntriesOfAuthors([]uint64{followedID}, pagination.Page{Limit: MaxLength - 1}); err != nil {
+		return err
+	}
+
+	followerIDs, err := src.FollowerIDs(followedID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range followerIDs {
+		if err = Default.Add(id, entries...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Read retorna uma página da linha do tempo do usuário, unindo as publicações pré-calculadas com as
+// das celebridades e das hashtags populares que ele segue. A linha do tempo é construída a partir do
+// banco de dados quando ainda não existe no armazenamento
+func Read(db *sql.DB, userID uint64, page pagination.Page) ([]model.Publication, string, error) {
+	entries, next, err := readEntries(newDBSource(db), userID, page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Publicações de usuários bloqueados ou silenciados são descartadas aqui, então uma página pode
+	// ter menos itens que o limite sem que a listagem tenha terminado
+	publications, err := repository.NewRepositoryOfPublications(db).GetByIDs(userID, entryIDs(entries))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return publications, next, nil
+}
+
+// readEntries retorna as entradas de uma página da linha do tempo do usuário e o cursor da próxima
+func readEntries(src source, userID uint64, page pagination.Page) ([]Entry, string, error) {
+	built, err := Default.Exists(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !built {
+		entries, err := src.TimelineEntries(userID, MaxLength)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if err = Default.Build(userID, entries...); err != nil {
+			return nil, "", err
+		}
+	}
+
+	stored, err := Default.Range(userID, page.After, page.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	celebrityIDs, err := src.FollowedCelebrities(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	celebrities, err := src.EntriesOfAuthors(celebrityIDs, page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	popularTagIDs, err := src.FollowedPopularTags(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	popularTags, err := src.EntriesOfTags(popularTagIDs, page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries := merge(stored, celebrities, popularTags)
+	entries, next := pagination.Trim(entries, entries, page.Limit)
+
+	return entries, next, nil
+}
+
+// OnFollowTag insere as publicações recentes com a hashtag na linha do tempo de quem passou a segui-la
+func OnFollowTag(db *sql.DB, userID, tagID uint64) error {
+	return onFollowTag(newDBSource(db), userID, tagID)
+}
+
+func onFollowTag(src source, userID, tagID uint64) error {
+	entries, err := src.EntriesOfTags([]uint64{tagID}, pagination.Page{Limit: backfillLength - 1})
+	if err != nil {
+		return err
+	}
+
+	return Default.Add(userID, entries...)
+}
+
+// OnUnfollowTag retira da linha do tempo as publicações que estavam nela apenas por causa da hashtag
+// que o usuário deixou de seguir
+func OnUnfollowTag(db *sql.DB, userID, tagID uint64) error {
+	return onUnfollowTag(newDBSource(db), userID, tagID)
+}
+
+func onUnfollowTag(src source, userID, tagID uint64) error {
+	entries, err := src.EntriesOnlyOfTag(userID, tagID, MaxLength)
+	if err != nil {
+		return err
+	}
+
+	return Default.Remove(userID, entryIDs(entries)...)
+}
+
+// entryIDs retorna os ids das publicações das entradas
+func entryIDs(entries []Entry) []uint64 {
+	publicationIDs := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		publicationIDs = append(publicationIDs, entry.ID)
+	}
+
+	return publicationIDs
+}
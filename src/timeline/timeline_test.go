@@ -0,0 +1,661 @@
+package timeline
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"api.devbook/src/config"
+	"api.devbook/src/model"
+	"api.devbook/src/pagination"
+)
+
+// reader é o usuário cuja linha do tempo é lida nos testes
+const reader = 1
+
+var base = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type fakePost struct {
+	entry    Entry
+	authorID uint64
+	tags     []string
+}
+
+// fakeSource reproduz em memória as consultas de source sobre um conjunto de publicações, usuários
+// seguidos e hashtags seguidas
+type fakeSource struct {
+	posts      []fakePost
+	follows    map[uint64][]uint64
+	tagFollows map[uint64][]string
+	tagIDs     map[string]uint64
+}
+
+func newFakeSource(follows map[uint64][]uint64, tagFollows map[uint64][]string) *fakeSource {
+	src := &fakeSource{follows: follows, tagFollows: tagFollows, tagIDs: make(map[string]uint64)}
+	for _, tags := range tagFollows {
+		for _, tag := range tags {
+			src.tagID(tag)
+		}
+	}
+
+	return src
+}
+
+func (s *fakeSource) tagID(name string) uint64 {
+	if _, ok := s.tagIDs[name]; !ok {
+		s.tagIDs[name] = uint64(len(s.tagIDs) + 1)
+	}
+
+	return s.tagIDs[name]
+}
+
+func (s *fakeSource) following(followerID, followedID uint64) bool {
+	for _, id := range s.follows[followerID] {
+		if id == followedID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *fakeSource) followingTag(userID, tagID uint64) bool {
+	for _, tag := range s.tagFollows[userID] {
+		if s.tagIDs[tag] == tagID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *fakeSource) tagFollowers(tagID uint64) []uint64 {
+	var userIDs []uint64
+	for userID := range s.tagFollows {
+		if s.followingTag(userID, tagID) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	return userIDs
+}
+
+// entries retorna as publicações que satisfazem o filtro, em ordem, posteriores ao cursor e até o limite
+func (s *fakeSource) entries(after *Entry, limit int, keep func(fakePost) bool) []Entry {
+	var entries []Entry
+	for _, post := range s.posts {
+		if keep(post) && (after == nil || newer(*after, post.entry)) {
+			entries = append(entries, post.entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return newer(entries[i], entries[j]) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries
+}
+
+func (s *fakeSource) hasTag(post fakePost, tagID uint64) bool {
+	for _, tag := range post.tags {
+		if s.tagIDs[tag] == tagID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *fakeSource) TimelineEntries(userID uint64, limit int) ([]Entry, error) {
+	return s.entries(nil, limit, func(post fakePost) bool {
+		if post.authorID == userID || s.following(userID, post.authorID) {
+			return true
+		}
+
+		for _, tag := range post.tags {
+			if s.followingTag(userID, s.tagIDs[tag]) {
+				return true
+			}
+		}
+
+		return false
+	}), nil
+}
+
+func (s *fakeSource) EntriesOfAuthors(authorIDs []uint64, page pagination.Page) ([]Entry, error) {
+	return s.entries(page.After, page.Limit+1, func(post fakePost) bool {
+		for _, authorID := range authorIDs {
+			if post.authorID == authorID {
+				return true
+			}
+		}
+
+		return false
+	}), nil
+}
+
+func (s *fakeSource) EntriesOfTags(tagIDs []uint64, page pagination.Page) ([]Entry, error) {
+	return s.entries(page.After, page.Limit+1, func(post fakePost) bool {
+		for _, tagID := range tagIDs {
+			if s.hasTag(post, tagID) {
+				return true
+			}
+		}
+
+		return false
+	}), nil
+}
+
+func (s *fakeSource) EntriesOnlyOfTag(userID, tagID uint64, limit int) ([]Entry, error) {
+	return s.entries(nil, limit, func(post fakePost) bool {
+		if !s.hasTag(post, tagID) || post.authorID == userID || s.following(userID, post.authorID) {
+			return false
+		}
+
+		for _, tag := range post.tags {
+			if s.tagIDs[tag] != tagID && s.followingTag(userID, s.tagIDs[tag]) {
+				return false
+			}
+		}
+
+		return true
+	}), nil
+}
+
+func (s *fakeSource) EntriesOnlyOfAuthor(userID, authorID uint64, limit int) ([]Entry, error) {
+	return s.entries(nil, limit, func(post fakePost) bool {
+		if post.authorID != authorID {
+			return false
+		}
+
+		for _, tag := range post.tags {
+			if s.followingTag(userID, s.tagIDs[tag]) {
+				return false
+			}
+		}
+
+		return true
+	}), nil
+}
+
+func (s *fakeSource) unfollow(t *testing.T, followerID, followedID uint64) {
+	t.Helper()
+
+	var kept []uint64
+	for _, id := range s.follows[followerID] {
+		if id != followedID {
+			kept = append(kept, id)
+		}
+	}
+	s.follows[followerID] = kept
+
+	if err := onUnfollow(s, followerID, followedID); err != nil {
+		t.Fatalf("onUnfollow(%d, %d): %v", followerID, followedID, err)
+	}
+}
+
+func (s *fakeSource) CountFollowers(userID uint64) (int, error) {
+	followerIDs, _ := s.FollowerIDs(userID)
+	return len(followerIDs), nil
+}
+
+func (s *fakeSource) FollowerIDs(userID uint64) ([]uint64, error) {
+	var followerIDs []uint64
+	for followerID := range s.follows {
+		if s.following(followerID, userID) {
+			followerIDs = append(followerIDs, followerID)
+		}
+	}
+
+	return followerIDs, nil
+}
+
+func (s *fakeSource) FollowedCelebrities(userID uint64) ([]uint64, error) {
+	var celebrityIDs []uint64
+	for _, followedID := range s.follows[userID] {
+		if followers, _ := s.CountFollowers(followedID); followers > config.CelebrityThreshold {
+			celebrityIDs = append(celebrityIDs, followedID)
+		}
+	}
+
+	return celebrityIDs, nil
+}
+
+func (s *fakeSource) TagFollowerIDs(tags []string) ([]uint64, error) {
+	seen := make(map[uint64]bool)
+
+	var userIDs []uint64
+	for _, tag := range tags {
+		followers := s.tagFollowers(s.tagID(tag))
+		if len(followers) > config.CelebrityThreshold {
+			continue
+		}
+
+		for _, userID := range followers {
+			if !seen[userID] {
+				seen[userID] = true
+				userIDs = append(userIDs, userID)
+			}
+		}
+	}
+
+	return userIDs, nil
+}
+
+func (s *fakeSource) FollowedPopularTags(userID uint64) ([]uint64, error) {
+	var tagIDs []uint64
+	for _, tag := range s.tagFollows[userID] {
+		if len(s.tagFollowers(s.tagIDs[tag])) > config.CelebrityThreshold {
+			tagIDs = append(tagIDs, s.tagIDs[tag])
+		}
+	}
+
+	return tagIDs, nil
+}
+
+// publish registra a publicação na source e a distribui como a API faz ao publicar
+func (s *fakeSource) publish(t *testing.T, post fakePost) {
+	t.Helper()
+
+	for _, tag := range post.tags {
+		s.tagID(tag)
+	}
+	s.posts = append(s.posts, post)
+
+	publication := model.Publication{
+		ID:        post.entry.ID,
+		AuthorID:  post.authorID,
+		CreatedAt: post.entry.CreatedAt,
+		Tags:      post.tags,
+	}
+
+	if err := onPublish(s, publication); err != nil {
+		t.Fatalf("onPublish(%d): %v", post.entry.ID, err)
+	}
+}
+
+// retag troca as hashtags da publicação e atualiza as linhas do tempo como a API faz após uma edição
+func (s *fakeSource) retag(t *testing.T, publicationID uint64, tags []string) {
+	t.Helper()
+
+	for i := range s.posts {
+		if s.posts[i].entry.ID != publicationID {
+			continue
+		}
+
+		removed := s.posts[i].tags
+		for _, tag := range tags {
+			s.tagID(tag)
+		}
+		s.posts[i].tags = tags
+
+		publication := model.Publication{
+			ID:        publicationID,
+			AuthorID:  s.posts[i].authorID,
+			CreatedAt: s.posts[i].entry.CreatedAt,
+			Tags:      tags,
+		}
+
+		if err := onPublish(s, publication); err != nil {
+			t.Fatalf("onPublish(%d): %v", publicationID, err)
+		}
+
+		if err := onUntag(s, publication, removed); err != nil {
+			t.Fatalf("onUntag(%d): %v", publicationID, err)
+		}
+
+		return
+	}
+
+	t.Fatalf("publicação %d não encontrada", publicationID)
+}
+
+func post(id, authorID uint64, minute int, tags ...string) fakePost {
+	return fakePost{
+		entry:    Entry{CreatedAt: base.Add(time.Duration(minute) * time.Minute), ID: id},
+		authorID: authorID,
+		tags:     tags,
+	}
+}
+
+// readAll lê a linha do tempo do leitor página a página, seguindo os cursores
+func readAll(t *testing.T, src source, limit int) []uint64 {
+	t.Helper()
+
+	page := pagination.Page{Limit: limit}
+
+	var ids []uint64
+	for i := 0; ; i++ {
+		if i > 100 {
+			t.Fatal("a paginação não terminou")
+		}
+
+		entries, next, err := readEntries(src, reader, page)
+		if err != nil {
+			t.Fatalf("readEntries: %v", err)
+		}
+
+		if len(entries) > limit {
+			t.Fatalf("página com %d entradas, limite %d", len(entries), limit)
+		}
+
+		ids = append(ids, entryIDs(entries)...)
+		if next == "" {
+			return ids
+		}
+
+		after, err := pagination.Decode(next)
+		if err != nil {
+			t.Fatalf("cursor inválido %q: %v", next, err)
+		}
+		page.After = &after
+	}
+}
+
+func setThreshold(t *testing.T, threshold int) {
+	previous := config.CelebrityThreshold
+	config.CelebrityThreshold = threshold
+	t.Cleanup(func() { config.CelebrityThreshold = previous })
+}
+
+func setStore(t *testing.T, store Store) {
+	previous := Default
+	Default = store
+	t.Cleanup(func() { Default = previous })
+}
+
+func TestReadTimeline(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  int
+		follows    map[uint64][]uint64
+		tagFollows map[uint64][]string
+		posts      []fakePost
+		want       []uint64
+	}{
+		{
+			name:  "próprias publicações sem seguir ninguém",
+			posts: []fakePost{post(1, reader, 1), post(2, 2, 2), post(3, reader, 3)},
+			want:  []uint64{3, 1},
+		},
+		{
+			name:    "usuários seguidos, mas não os seguidores",
+			follows: map[uint64][]uint64{reader: {2}, 3: {reader}},
+			posts:   []fakePost{post(1, 2, 1), post(2, 3, 2), post(3, 4, 3), post(4, 2, 4)},
+			want:    []uint64{4, 1},
+		},
+		{
+			name:       "hashtags seguidas",
+			tagFollows: map[uint64][]string{reader: {"go"}, 4: {"sql"}},
+			posts:      []fakePost{post(1, 3, 1, "go"), post(2, 3, 2, "sql"), post(3, 3, 3, "go", "sql")},
+			want:       []uint64{3, 1},
+		},
+		{
+			name:      "celebridades buscadas na leitura",
+			threshold: 1,
+			follows:   map[uint64][]uint64{reader: {2, 3}, 4: {2}},
+			posts:     []fakePost{post(1, 2, 1), post(2, 3, 2), post(3, 2, 3)},
+			want:      []uint64{3, 2, 1},
+		},
+		{
+			name:       "hashtags populares buscadas na leitura",
+			threshold:  1,
+			tagFollows: map[uint64][]string{reader: {"go", "sql"}, 4: {"go"}},
+			posts:      []fakePost{post(1, 3, 1, "go"), post(2, 3, 2, "sql"), post(3, 3, 3, "rust")},
+			want:       []uint64{2, 1},
+		},
+		{
+			name:       "sem repetição entre autor seguido e hashtags seguidas",
+			follows:    map[uint64][]uint64{reader: {2}},
+			tagFollows: map[uint64][]string{reader: {"go", "sql"}},
+			posts:      []fakePost{post(1, 2, 1, "go", "sql"), post(2, reader, 2, "go")},
+			want:       []uint64{2, 1},
+		},
+		{
+			name:       "sem repetição entre celebridade e hashtag popular",
+			threshold:  1,
+			follows:    map[uint64][]uint64{reader: {2}, 4: {2}},
+			tagFollows: map[uint64][]string{reader: {"go"}, 4: {"go"}},
+			posts:      []fakePost{post(1, 2, 1, "go"), post(2, 2, 2), post(3, 3, 3, "go")},
+			want:       []uint64{3, 2, 1},
+		},
+		{
+			name:       "mesmo instante desempatado pelo id",
+			threshold:  1,
+			follows:    map[uint64][]uint64{reader: {2, 3}, 4: {3}},
+			tagFollows: map[uint64][]string{reader: {"go"}},
+			posts: []fakePost{
+				post(1, 2, 5), post(2, 3, 5), post(3, 5, 5, "go"), post(4, reader, 5), post(5, 2, 4),
+			},
+			want: []uint64{4, 3, 2, 1, 5},
+		},
+	}
+
+	modes := []struct {
+		name string
+		// fanOut indica se as publicações são distribuídas depois de a linha do tempo existir, em vez
+		// de já estarem no banco de dados quando ela é construída
+		fanOut bool
+	}{
+		{name: "construída do banco"},
+		{name: "distribuída na publicação", fanOut: true},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range modes {
+			t.Run(tt.name+"/"+mode.name, func(t *testing.T) {
+				// Sem um limite informado, ninguém é celebridade e nenhuma hashtag é popular
+				threshold := tt.threshold
+				if threshold == 0 {
+					threshold = 10
+				}
+				setThreshold(t, threshold)
+				setStore(t, NewMemory())
+
+				src := newFakeSource(tt.follows, tt.tagFollows)
+				if mode.fanOut {
+					if got := readAll(t, src, 10); len(got) != 0 {
+						t.Fatalf("linha do tempo vazia = %v", got)
+					}
+				}
+
+				for _, p := range tt.posts {
+					if mode.fanOut {
+						src.publish(t, p)
+					} else {
+						src.posts = append(src.posts, p)
+					}
+				}
+
+				if got := readAll(t, src, 10); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("linha do tempo = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestReadTimelineCursor(t *testing.T) {
+	setThreshold(t, 1)
+
+	// As publicações vêm das três origens, com vários empates na data de criação entre elas
+	follows := map[uint64][]uint64{reader: {2, 3}, 4: {3}}
+	tagFollows := map[uint64][]string{reader: {"go"}, 4: {"go"}}
+
+	var posts []fakePost
+	for id := uint64(1); id <= 30; id++ {
+		minute := int(id / 3)
+		switch id % 3 {
+		case 0:
+			posts = append(posts, post(id, 2, minute))
+		case 1:
+			posts = append(posts, post(id, 3, minute))
+		default:
+			posts = append(posts, post(id, 5, minute, "go"))
+		}
+	}
+
+	entries := make([]Entry, 0, len(posts))
+	for _, p := range posts {
+		entries = append(entries, p.entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return newer(entries[i], entries[j]) })
+	want := entryIDs(entries)
+
+	for _, limit := range []int{1, 2, 3, 7, 30, 50} {
+		setStore(t, NewMemory())
+
+		src := newFakeSource(follows, tagFollows)
+		src.posts = posts
+
+		if got := readAll(t, src, limit); !reflect.DeepEqual(got, want) {
+			t.Errorf("limit %d: linha do tempo = %v, want %v", limit, got, want)
+		}
+	}
+}
+
+func TestUnfollow(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  int
+		follows    map[uint64][]uint64
+		tagFollows map[uint64][]string
+		posts      []fakePost
+		unfollows  [][2]uint64
+		want       []uint64
+	}{
+		{
+			name:      "retira as publicações de quem deixou de ser seguido",
+			threshold: 10,
+			follows:   map[uint64][]uint64{reader: {2, 3}},
+			posts:     []fakePost{post(1, 2, 1), post(2, 3, 2), post(3, 2, 3)},
+			unfollows: [][2]uint64{{reader, 2}},
+			want:      []uint64{2},
+		},
+		{
+			name:       "mantém as publicações com hashtags seguidas",
+			threshold:  10,
+			follows:    map[uint64][]uint64{reader: {2}},
+			tagFollows: map[uint64][]string{reader: {"go"}},
+			posts:      []fakePost{post(1, 2, 1, "go"), post(2, 2, 2), post(3, 2, 3, "sql", "go")},
+			unfollows:  [][2]uint64{{reader, 2}},
+			want:       []uint64{3, 1},
+		},
+		{
+			name:      "celebridade que deixa de ser celebridade",
+			threshold: 1,
+			follows:   map[uint64][]uint64{reader: {2}, 4: {2}, 5: {2}},
+			posts:     []fakePost{post(1, 2, 1), post(2, 2, 2)},
+			unfollows: [][2]uint64{{5, 2}, {4, 2}},
+			want:      []uint64{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setThreshold(t, tt.threshold)
+			setStore(t, NewMemory())
+
+			src := newFakeSource(tt.follows, tt.tagFollows)
+			readAll(t, src, 10)
+
+			for _, p := range tt.posts {
+				src.publish(t, p)
+			}
+
+			for _, unfollow := range tt.unfollows {
+				src.unfollow(t, unfollow[0], unfollow[1])
+			}
+
+			if got := readAll(t, src, 10); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("linha do tempo = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUntag(t *testing.T) {
+	tests := []struct {
+		name       string
+		follows    map[uint64][]uint64
+		tagFollows map[uint64][]string
+		tags       []string
+		want       []uint64
+	}{
+		{
+			name:       "retira de quem seguia apenas a hashtag removida",
+			tagFollows: map[uint64][]string{reader: {"go"}},
+			tags:       []string{"sql"},
+		},
+		{
+			name:       "mantém para quem segue uma hashtag que restou",
+			tagFollows: map[uint64][]string{reader: {"go", "sql"}},
+			tags:       []string{"sql"},
+			want:       []uint64{1},
+		},
+		{
+			name:       "mantém para quem segue o autor",
+			follows:    map[uint64][]uint64{reader: {2}},
+			tagFollows: map[uint64][]string{reader: {"go"}},
+			want:       []uint64{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setThreshold(t, 10)
+			setStore(t, NewMemory())
+
+			src := newFakeSource(tt.follows, tt.tagFollows)
+			readAll(t, src, 10)
+
+			src.publish(t, post(1, 2, 1, "go", "sql"))
+			src.retag(t, 1, tt.tags)
+
+			if got := readAll(t, src, 10); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("linha do tempo = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	at := func(minute int, id uint64) Entry {
+		return Entry{CreatedAt: base.Add(time.Duration(minute) * time.Minute), ID: id}
+	}
+
+	tests := []struct {
+		name  string
+		lists [][]Entry
+		want  []Entry
+	}{
+		{
+			name: "vazio",
+		},
+		{
+			name:  "ordena da mais recente para a mais antiga",
+			lists: [][]Entry{{at(1, 1), at(3, 3)}, {at(2, 2)}},
+			want:  []Entry{at(3, 3), at(2, 2), at(1, 1)},
+		},
+		{
+			name:  "desempata pelo maior id",
+			lists: [][]Entry{{at(1, 1)}, {at(1, 3)}, {at(1, 2)}},
+			want:  []Entry{at(1, 3), at(1, 2), at(1, 1)},
+		},
+		{
+			name:  "descarta as repetidas",
+			lists: [][]Entry{{at(2, 2), at(1, 1)}, {at(2, 2)}, {at(1, 1), at(3, 3)}},
+			want:  []Entry{at(3, 3), at(2, 2), at(1, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := merge(tt.lists...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
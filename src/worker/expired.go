@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"database/sql"
+	"errors"
+
+	"api.devbook/src/database"
+	"api.devbook/src/publishing"
+	"api.devbook/src/repository"
+)
+
+// expiredBatchSize é a quantidade máxima de publicações expiradas excluídas a cada execução
+const expiredBatchSize = 100
+
+// DeleteExpired exclui definitivamente as publicações publicadas que expiraram, junto com as suas
+// reações, os seus reposts e as suas mídias. Elas já não aparecem nas consultas desde que expiraram
+func DeleteExpired() error {
+	db, err := database.Connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfPublications(db)
+	expired, err := repo.GetExpired(expiredBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, publication := range expired {
+		// Os reposts são excluídos em cascata e precisam ser lidos antes
+		reposts, err := repo.GetReposts([]uint64{publication.ID})
+		if err != nil {
+			return err
+		}
+
+		if err = repo.Delete(publication.ID); err != nil {
+			// Outra instância da API pode ter excluído a publicação ao mesmo tempo
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+
+			return err
+		}
+
+		for _, deleted := range append(reposts, publication) {
+			publishing.OnDelete(db, deleted)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"time"
+
+	"api.devbook/src/database"
+	"api.devbook/src/media"
+	"api.devbook/src/repository"
+)
+
+// orphanMediaTTL é o tempo que um anexo enviado pode permanecer sem ser associado a uma publicação
+const orphanMediaTTL = 24 * time.Hour
+
+// CollectOrphanMedia exclui os anexos enviados que não foram associados a nenhuma publicação
+func CollectOrphanMedia() error {
+	db, err := database.Connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfMedia(db)
+	orphans, err := repo.GetOrphans(time.Now().Add(-orphanMediaTTL))
+	if err != nil {
+		return err
+	}
+
+	for _, orphan := range orphans {
+		if err = repo.Delete(orphan.ID); err != nil {
+			return err
+		}
+
+		if err = media.Remove(orphan.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
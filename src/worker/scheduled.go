@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"api.devbook/src/database"
+	"api.devbook/src/publishing"
+	"api.devbook/src/repository"
+)
+
+// scheduledBatchSize é a quantidade máxima de publicações agendadas publicadas a cada execução
+const scheduledBatchSize = 100
+
+// PublishScheduled publica as publicações agendadas cuja data já chegou. Como as pendentes são lidas do
+// banco de dados, as que venceram enquanto a API estava parada são publicadas na próxima execução
+func PublishScheduled() error {
+	db, err := database.Connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := repository.NewRepositoryOfPublications(db)
+	publicationIDs, err := repo.PublishDue(scheduledBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, publicationID := range publicationIDs {
+		publication, err := repo.GetById(publicationID)
+		if err != nil {
+			return err
+		}
+
+		if publication.ID != 0 {
+			publishing.OnPublish(db, publication)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"log"
+	"time"
+)
+
+// Every executa a tarefa periodicamente em segundo plano, registrando no log os erros retornados
+func Every(interval time.Duration, name string, task func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := task(); err != nil {
+				log.Printf("Erro na tarefa %s: %v", name, err)
+			}
+		}
+	}()
+}